@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"cxtv-alerts/internal/database"
+	"cxtv-alerts/internal/model"
+)
+
+// runExport writes the recorded live sessions as JSON or CSV.
+func runExport(args []string) int {
+	fs, o := newFlagSet("export")
+	format := fs.String("format", "json", "output format: json or csv")
+	output := fs.String("o", "", "write to `file` instead of stdout")
+	streamerID := fs.String("streamer", "", "only export sessions of this streamer `id`")
+	since := fs.String("since", "", "only export sessions started on or after this `date` (YYYY-MM-DD)")
+	if err := o.parse(fs, args); err != nil {
+		return flagError(err)
+	}
+	if fs.NArg() > 0 || (*format != "json" && *format != "csv") {
+		fs.Usage()
+		return 2
+	}
+
+	var sinceTime time.Time
+	if *since != "" {
+		t, err := time.ParseInLocation("2006-01-02", *since, time.Local)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "export: invalid -since date: %s\n", *since)
+			return 2
+		}
+		sinceTime = t
+	}
+
+	// Opening a missing database would create an empty one
+	dbPath := o.database()
+	if _, err := os.Stat(dbPath); err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return 1
+	}
+	db, err := database.New(dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	sessions, err := db.GetSessions(*streamerID, sinceTime)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return 1
+	}
+
+	out := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "export: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if *format == "csv" {
+		err = writeSessionsCSV(out, sessions)
+	} else {
+		enc := json.NewEncoder(out)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if sessions == nil {
+			sessions = []model.LiveSession{}
+		}
+		err = enc.Encode(sessions)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return 1
+	}
+	if *output != "" {
+		fmt.Fprintf(os.Stderr, "Exported %d sessions to %s\n", len(sessions), *output)
+	}
+	return 0
+}
+
+// writeSessionsCSV writes one row per session. Title changes only appear as
+// a count, the JSON export has them in full.
+func writeSessionsCSV(w io.Writer, sessions []model.LiveSession) error {
+	const timeFormat = "2006-01-02 15:04:05"
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		"id", "streamer_id", "platform", "room_id", "title",
+		"start_time", "end_time", "duration", "end_reason",
+		"peak_viewers", "avg_viewers", "title_changes",
+	})
+	for _, s := range sessions {
+		endTime := ""
+		if s.EndTime != nil {
+			endTime = s.EndTime.Format(timeFormat)
+		}
+		titleChanges := 0
+		if len(s.Titles) > 1 {
+			titleChanges = len(s.Titles) - 1
+		}
+		cw.Write([]string{
+			strconv.FormatInt(s.ID, 10), s.StreamerID, string(s.Platform), s.RoomID, s.Title,
+			s.StartTime.Format(timeFormat), endTime, strconv.FormatInt(s.Duration, 10), s.EndReason,
+			strconv.FormatInt(s.PeakViewers, 10), strconv.FormatInt(s.AvgViewers, 10), strconv.Itoa(titleChanges),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// runMigrate brings the database schema up to date and checks the file for
+// corruption, optionally compacting it afterwards. Run it with the server
+// stopped.
+func runMigrate(args []string) int {
+	fs, o := newFlagSet("migrate")
+	vacuum := fs.Bool("vacuum", false, "rebuild the database file to reclaim unused space")
+	if err := o.parse(fs, args); err != nil {
+		return flagError(err)
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+
+	dbPath := o.database()
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+		return 1
+	}
+	// Opening the database applies pending migrations
+	db, err := database.New(dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+	fmt.Println("Schema up to date")
+
+	if err := db.IntegrityCheck(); err != nil {
+		fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+		return 1
+	}
+	fmt.Println("Integrity check passed")
+
+	if *vacuum {
+		before := fileSize(dbPath)
+		if err := db.Vacuum(); err != nil {
+			fmt.Fprintf(os.Stderr, "migrate: vacuum: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Vacuumed %s: %d -> %d bytes\n", dbPath, before, fileSize(dbPath))
+	}
+	return 0
+}
+
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
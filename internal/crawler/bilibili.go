@@ -1,6 +1,7 @@
 package crawler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -15,9 +16,7 @@ type BilibiliCrawler struct {
 
 func NewBilibiliCrawler() *BilibiliCrawler {
 	return &BilibiliCrawler{
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		client: newClient(nil),
 	}
 }
 
@@ -51,28 +50,28 @@ type bilibiliUserResponse struct {
 	} `json:"data"`
 }
 
-func (c *BilibiliCrawler) GetLiveStatus(roomID string) (*model.Streamer, error) {
+func (c *BilibiliCrawler) GetLiveStatus(ctx context.Context, roomID string) (*model.Streamer, error) {
 	url := fmt.Sprintf("https://api.live.bilibili.com/room/v1/Room/get_info?room_id=%s", roomID)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
 
-	resp, err := c.client.Do(req)
+	resp, err := do(c.client, req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	var result bilibiliResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSON(resp, &result); err != nil {
 		return nil, err
 	}
 
 	if result.Code != 0 {
-		return nil, fmt.Errorf("bilibili API error: %s", result.Message)
+		return nil, fmt.Errorf("%w: bilibili API error %d: %s", bilibiliErrorKind(result.Code), result.Code, result.Message)
 	}
 
 	streamer := &model.Streamer{
@@ -91,7 +90,7 @@ func (c *BilibiliCrawler) GetLiveStatus(roomID string) (*model.Streamer, error)
 
 	// Get user info for avatar
 	userURL := fmt.Sprintf("https://api.live.bilibili.com/live_user/v1/UserInfo/get_anchor_in_room?roomid=%s", roomID)
-	userReq, err := http.NewRequest("GET", userURL, nil)
+	userReq, err := http.NewRequestWithContext(ctx, "GET", userURL, nil)
 	if err == nil {
 		userReq.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
 		userResp, err := c.client.Do(userReq)
@@ -109,3 +108,17 @@ func (c *BilibiliCrawler) GetLiveStatus(roomID string) (*model.Streamer, error)
 
 	return streamer, nil
 }
+
+// bilibiliErrorKind classifies the API's error codes.
+func bilibiliErrorKind(code int) error {
+	switch code {
+	case 1, 60004: // room does not exist
+		return ErrNotFound
+	case -412, -352: // request intercepted by risk control
+		return ErrBlocked
+	case -509, -799: // request too frequent
+		return ErrRateLimited
+	default:
+		return ErrParse
+	}
+}
@@ -1,12 +1,11 @@
 package crawler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"regexp"
-	"time"
 
 	"cxtv-alerts/internal/model"
 )
@@ -17,9 +16,7 @@ type CC163Crawler struct {
 
 func NewCC163Crawler() *CC163Crawler {
 	return &CC163Crawler{
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		client: newClient(nil),
 	}
 }
 
@@ -27,27 +24,30 @@ func (c *CC163Crawler) Platform() model.Platform {
 	return model.PlatformCC163
 }
 
-func (c *CC163Crawler) GetLiveStatus(roomID string) (*model.Streamer, error) {
+func (c *CC163Crawler) GetLiveStatus(ctx context.Context, roomID string) (*model.Streamer, error) {
 	url := fmt.Sprintf("https://cc.163.com/%s/", roomID)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
 
-	resp, err := c.client.Do(req)
+	resp, err := do(c.client, req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readBody(resp)
 	if err != nil {
 		return nil, err
 	}
 
 	html := string(body)
+	if err := checkBlocked(resp, html); err != nil {
+		return nil, err
+	}
 
 	streamer := &model.Streamer{
 		Platform: model.PlatformCC163,
@@ -102,11 +102,11 @@ func (c *CC163Crawler) GetLiveStatus(roomID string) (*model.Streamer, error) {
 
 	// Fallback title extraction
 	if streamer.Title == "" {
-		titleRe := regexp.MustCompile(`<title>([^<]+)</title>`)
-		if matches := titleRe.FindStringSubmatch(html); len(matches) > 1 {
-			streamer.Title = matches[1]
-		}
+		streamer.Title = pageTitle(html)
 	}
 
+	if streamer.Title == "" && streamer.Name == "" {
+		return nil, errNoRoomData
+	}
 	return streamer, nil
 }
@@ -1,8 +1,38 @@
 package crawler
 
-import "cxtv-alerts/internal/model"
+import (
+	"context"
 
+	"cxtv-alerts/internal/model"
+)
+
+// Crawler queries the live status of rooms on one platform. Implementations
+// carry no timeouts of their own; ctx bounds every request they make.
 type Crawler interface {
-	GetLiveStatus(roomID string) (*model.Streamer, error)
+	GetLiveStatus(ctx context.Context, roomID string) (*model.Streamer, error)
 	Platform() model.Platform
 }
+
+var constructors = map[model.Platform]func() Crawler{
+	model.PlatformBilibili: func() Crawler { return NewBilibiliCrawler() },
+	model.PlatformDouyu:    func() Crawler { return NewDouyuCrawler() },
+	model.PlatformDouyin:   func() Crawler { return NewDouyinCrawler() },
+	model.PlatformKuaishou: func() Crawler { return NewKuaishouCrawler() },
+	model.PlatformCC163:    func() Crawler { return NewCC163Crawler() },
+	model.PlatformWeibo:    func() Crawler { return NewWeiboCrawler() },
+}
+
+// All returns one crawler per supported platform.
+func All() map[model.Platform]Crawler {
+	crawlers := make(map[model.Platform]Crawler, len(constructors))
+	for p, newCrawler := range constructors {
+		crawlers[p] = newCrawler()
+	}
+	return crawlers
+}
+
+// Supported reports whether there is a crawler for platform p.
+func Supported(p model.Platform) bool {
+	_, ok := constructors[p]
+	return ok
+}
@@ -1,12 +1,11 @@
 package crawler
 
 import (
+	"context"
 	"fmt"
-	"io"
 	"net/http"
 	"regexp"
 	"strings"
-	"time"
 
 	"cxtv-alerts/internal/model"
 )
@@ -17,9 +16,7 @@ type DouyinCrawler struct {
 
 func NewDouyinCrawler() *DouyinCrawler {
 	return &DouyinCrawler{
-		client: &http.Client{
-			Timeout: 15 * time.Second,
-		},
+		client: newClient(nil),
 	}
 }
 
@@ -27,10 +24,10 @@ func (c *DouyinCrawler) Platform() model.Platform {
 	return model.PlatformDouyin
 }
 
-func (c *DouyinCrawler) GetLiveStatus(roomID string) (*model.Streamer, error) {
+func (c *DouyinCrawler) GetLiveStatus(ctx context.Context, roomID string) (*model.Streamer, error) {
 	url := fmt.Sprintf("https://live.douyin.com/%s", roomID)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -39,18 +36,21 @@ func (c *DouyinCrawler) GetLiveStatus(roomID string) (*model.Streamer, error) {
 	req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9")
 	req.Header.Set("Cookie", "__ac_nonce=0123456789")
 
-	resp, err := c.client.Do(req)
+	resp, err := do(c.client, req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readBody(resp)
 	if err != nil {
 		return nil, err
 	}
 
 	html := string(body)
+	if err := checkBlocked(resp, html); err != nil {
+		return nil, err
+	}
 
 	streamer := &model.Streamer{
 		Platform: model.PlatformDouyin,
@@ -65,9 +65,7 @@ func (c *DouyinCrawler) GetLiveStatus(roomID string) (*model.Streamer, error) {
 	}
 
 	// Extract title
-	titleRe := regexp.MustCompile(`<title>([^<]+)</title>`)
-	if matches := titleRe.FindStringSubmatch(html); len(matches) > 1 {
-		title := matches[1]
+	if title := pageTitle(html); title != "" {
 		title = strings.TrimSuffix(title, " - 抖音直播")
 		title = strings.TrimSuffix(title, "_抖音直播")
 		streamer.Title = title
@@ -85,5 +83,8 @@ func (c *DouyinCrawler) GetLiveStatus(roomID string) (*model.Streamer, error) {
 		streamer.Avatar = strings.ReplaceAll(matches[1], "\\u0026", "&")
 	}
 
+	if streamer.Title == "" && streamer.Name == "" {
+		return nil, errNoRoomData
+	}
 	return streamer, nil
 }
@@ -1,7 +1,7 @@
 package crawler
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/http"
 	"time"
@@ -15,9 +15,7 @@ type DouyuCrawler struct {
 
 func NewDouyuCrawler() *DouyuCrawler {
 	return &DouyuCrawler{
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		client: newClient(nil),
 	}
 }
 
@@ -45,28 +43,31 @@ type douyuResponse struct {
 	} `json:"room"`
 }
 
-func (c *DouyuCrawler) GetLiveStatus(roomID string) (*model.Streamer, error) {
+func (c *DouyuCrawler) GetLiveStatus(ctx context.Context, roomID string) (*model.Streamer, error) {
 	url := fmt.Sprintf("https://www.douyu.com/betard/%s", roomID)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
 
-	resp, err := c.client.Do(req)
+	resp, err := do(c.client, req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	var result douyuResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSON(resp, &result); err != nil {
 		return nil, err
 	}
 
 	if result.Error != 0 {
-		return nil, fmt.Errorf("douyu API error: %d", result.Error)
+		return nil, fmt.Errorf("%w: douyu API error %d", ErrParse, result.Error)
+	}
+	if result.Room.RoomID == 0 {
+		return nil, fmt.Errorf("%w: douyu response without room", ErrNotFound)
 	}
 
 	avatar := result.Room.AvatarMid
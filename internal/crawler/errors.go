@@ -0,0 +1,145 @@
+package crawler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Kinds of query failures. Crawlers wrap every error they return in one of
+// these, so callers can tell a flaky network from a platform change.
+var (
+	ErrRateLimited = errors.New("rate limited")
+	ErrNotFound    = errors.New("room not found")
+	ErrBlocked     = errors.New("blocked by captcha or risk control")
+	ErrRejected    = errors.New("request rejected")
+	ErrParse       = errors.New("unexpected response")
+	ErrTransport   = errors.New("transport error")
+)
+
+var kinds = []struct {
+	err  error
+	name string
+}{
+	{ErrRateLimited, "rate_limited"},
+	{ErrNotFound, "not_found"},
+	{ErrBlocked, "blocked"},
+	{ErrRejected, "rejected"},
+	{ErrParse, "parse"},
+	{ErrTransport, "transport"},
+}
+
+// Kind returns the name of err's kind as stored and shown in the API:
+// rate_limited, not_found, blocked, rejected, parse, transport, or unknown for errors
+// no crawler classified.
+func Kind(err error) string {
+	for _, k := range kinds {
+		if errors.Is(err, k.err) {
+			return k.name
+		}
+	}
+	return "unknown"
+}
+
+// do sends req and classifies failures: transport errors and 5xx as
+// ErrTransport, 429 as ErrRateLimited, 404/410 as ErrNotFound, 403 and 412
+// (Bilibili's risk control) as ErrBlocked and any other 4xx as
+// ErrRejected. Other non-2xx statuses are unexpected.
+func do(client *http.Client, req *http.Request) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTransport, err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	kind := ErrParse
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		kind = ErrRateLimited
+	case resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusGone:
+		kind = ErrNotFound
+	case resp.StatusCode == http.StatusForbidden, resp.StatusCode == http.StatusPreconditionFailed:
+		kind = ErrBlocked
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		kind = ErrRejected
+	case resp.StatusCode >= 500:
+		kind = ErrTransport
+	}
+	return nil, fmt.Errorf("%w: HTTP %d from %s", kind, resp.StatusCode, req.URL.Host)
+}
+
+// readBody reads a whole response body, classifying read errors as
+// transport errors.
+func readBody(resp *http.Response) ([]byte, error) {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTransport, err)
+	}
+	return body, nil
+}
+
+// decodeJSON decodes a JSON response body. A connection dropping midway is
+// a transport error, anything else means the format changed.
+func decodeJSON(resp *http.Response, v any) error {
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) {
+			return fmt.Errorf("%w: %w", ErrTransport, err)
+		}
+		return fmt.Errorf("%w: %w", ErrParse, err)
+	}
+	return nil
+}
+
+// verificationHosts serve the captcha pages platforms redirect to instead
+// of a room when they suspect a bot.
+var verificationHosts = []string{
+	"verify.snssdk.com",       // Douyin
+	"rmc.bytedance.com",       // Douyin
+	"captcha.zt.kuaishou.com", // Kuaishou
+}
+
+// verificationTitles are the complete titles of the captcha pages platforms
+// serve in place of a room. Room pages are titled after the streamer's own
+// room title, so only exact matches count.
+var verificationTitles = []string{
+	"验证码中间页", // Douyin
+}
+
+var titleRe = regexp.MustCompile(`<title>([^<]+)</title>`)
+
+// pageTitle returns the contents of an HTML page's title element.
+func pageTitle(html string) string {
+	if matches := titleRe.FindStringSubmatch(html); len(matches) > 1 {
+		return strings.TrimSpace(matches[1])
+	}
+	return ""
+}
+
+// checkBlocked returns ErrBlocked if the response, after redirects, is a
+// verification page rather than a room.
+func checkBlocked(resp *http.Response, html string) error {
+	if resp.Request != nil {
+		host := resp.Request.URL.Hostname()
+		if slices.Contains(verificationHosts, host) {
+			return fmt.Errorf("%w: redirected to %s", ErrBlocked, host)
+		}
+	}
+	if title := pageTitle(html); slices.Contains(verificationTitles, title) {
+		return fmt.Errorf("%w: verification page %q", ErrBlocked, title)
+	}
+	return nil
+}
+
+// errNoRoomData is returned for pages without anything the crawler
+// recognises, usually because the platform changed its markup.
+var errNoRoomData = fmt.Errorf("%w: no room data in page", ErrParse)
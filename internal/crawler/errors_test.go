@@ -0,0 +1,75 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDoClassifiesStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		kind   string
+	}{
+		{http.StatusTooManyRequests, "rate_limited"},
+		{http.StatusNotFound, "not_found"},
+		{http.StatusGone, "not_found"},
+		{http.StatusForbidden, "blocked"},
+		{http.StatusPreconditionFailed, "blocked"},
+		{http.StatusBadRequest, "rejected"},
+		{http.StatusUnauthorized, "rejected"},
+		{http.StatusTeapot, "rejected"},
+		{http.StatusBadGateway, "transport"},
+		{http.StatusServiceUnavailable, "transport"},
+		{http.StatusMultipleChoices, "parse"},
+	}
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+		}))
+		req, _ := http.NewRequest("GET", srv.URL, nil)
+		_, err := do(srv.Client(), req)
+		srv.Close()
+		if got := Kind(err); got != tt.kind {
+			t.Errorf("HTTP %d: got kind %q, want %q (%v)", tt.status, got, tt.kind, err)
+		}
+	}
+}
+
+func TestDoTransportError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", "http://127.0.0.1:1", nil)
+	_, err := do(http.DefaultClient, req)
+	if Kind(err) != "transport" {
+		t.Errorf("got kind %q, want transport (%v)", Kind(err), err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("context error not preserved: %v", err)
+	}
+}
+
+func TestCheckBlocked(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string // final URL after redirects
+		html    string
+		blocked bool
+	}{
+		{"douyin captcha page", "https://live.douyin.com/123", `<html><title>验证码中间页</title></html>`, true},
+		{"kuaishou captcha redirect", "https://captcha.zt.kuaishou.com/iframe/index.html", `<html><title>快手</title></html>`, true},
+		{"room page", "https://live.douyin.com/123", `<html><title>某主播的直播间 - 抖音直播</title></html>`, false},
+		{"room titled with 验证", "https://live.douyin.com/123", `<html><title>xx验证 - 抖音直播</title></html>`, false},
+		{"room titled with verify", "https://live.kuaishou.com/u/abc", `<html><title>verify my build | 快手直播</title></html>`, false},
+		{"room titled like the captcha page", "https://live.douyin.com/123", `<html><title>验证码中间页 - 抖音直播</title></html>`, false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.url, nil)
+		err := checkBlocked(&http.Response{Request: req}, tt.html)
+		if blocked := Kind(err) == "blocked"; blocked != tt.blocked || (!tt.blocked && err != nil) {
+			t.Errorf("%s: got %v, want blocked %v", tt.name, err, tt.blocked)
+		}
+	}
+}
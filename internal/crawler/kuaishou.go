@@ -1,14 +1,13 @@
 package crawler
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"regexp"
 	"strings"
-	"time"
 
 	"cxtv-alerts/internal/model"
 )
@@ -27,10 +26,7 @@ func NewKuaishouCrawler() *KuaishouCrawler {
 	}
 
 	return &KuaishouCrawler{
-		client: &http.Client{
-			Timeout:   30 * time.Second,
-			Transport: transport,
-		},
+		client: newClient(transport),
 	}
 }
 
@@ -38,10 +34,10 @@ func (c *KuaishouCrawler) Platform() model.Platform {
 	return model.PlatformKuaishou
 }
 
-func (c *KuaishouCrawler) GetLiveStatus(roomID string) (*model.Streamer, error) {
+func (c *KuaishouCrawler) GetLiveStatus(ctx context.Context, roomID string) (*model.Streamer, error) {
 	url := fmt.Sprintf("https://live.kuaishou.com/u/%s", roomID)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -61,18 +57,21 @@ func (c *KuaishouCrawler) GetLiveStatus(roomID string) (*model.Streamer, error)
 	req.Header.Set("Sec-Fetch-User", "?1")
 	req.Header.Set("Upgrade-Insecure-Requests", "1")
 
-	resp, err := c.client.Do(req)
+	resp, err := do(c.client, req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readBody(resp)
 	if err != nil {
 		return nil, err
 	}
 
 	html := string(body)
+	if err := checkBlocked(resp, html); err != nil {
+		return nil, err
+	}
 
 	streamer := &model.Streamer{
 		Platform: model.PlatformKuaishou,
@@ -112,14 +111,14 @@ func (c *KuaishouCrawler) GetLiveStatus(roomID string) (*model.Streamer, error)
 
 	// Extract title from page title
 	if streamer.Title == "" {
-		titleRe := regexp.MustCompile(`<title>([^<]+)</title>`)
-		if matches := titleRe.FindStringSubmatch(html); len(matches) > 1 {
-			title := matches[1]
-			title = strings.TrimSuffix(title, " - 快手直播")
-			title = strings.TrimSuffix(title, " - 快手")
-			streamer.Title = title
-		}
+		title := pageTitle(html)
+		title = strings.TrimSuffix(title, " - 快手直播")
+		title = strings.TrimSuffix(title, " - 快手")
+		streamer.Title = title
 	}
 
+	if streamer.Title == "" && streamer.Name == "" {
+		return nil, errNoRoomData
+	}
 	return streamer, nil
 }
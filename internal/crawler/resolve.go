@@ -0,0 +1,159 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"cxtv-alerts/internal/model"
+)
+
+// ErrUnsupportedURL is returned for links no crawler can track.
+var ErrUnsupportedURL = errors.New("unsupported room URL")
+
+// Room identifies a live room the way the crawlers address it.
+type Room struct {
+	Platform model.Platform
+	RoomID   string
+	URL      string // canonical link to the room
+}
+
+var (
+	numericID = regexp.MustCompile(`^\d+$`)
+	pathID    = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// ParseRoomURL detects the platform of a pasted room link and extracts the
+// room ID its crawler expects. The scheme may be omitted.
+func ParseRoomURL(raw string) (Room, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return Room{}, fmt.Errorf("%w: %s", ErrUnsupportedURL, raw)
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	host = strings.TrimPrefix(host, "m.")
+	var segments []string
+	for _, seg := range strings.Split(u.Path, "/") {
+		if seg != "" {
+			segments = append(segments, seg)
+		}
+	}
+	// last returns the final path segment if the path starts with prefix
+	last := func(prefix ...string) string {
+		if len(segments) != len(prefix)+1 {
+			return ""
+		}
+		for i, p := range prefix {
+			if segments[i] != p {
+				return ""
+			}
+		}
+		return segments[len(prefix)]
+	}
+
+	var room Room
+	switch host {
+	case "live.bilibili.com":
+		// live.bilibili.com/123, live.bilibili.com/h5/123
+		id := last()
+		if id == "" {
+			id = last("h5")
+		}
+		if numericID.MatchString(id) {
+			room = Room{model.PlatformBilibili, id, "https://live.bilibili.com/" + id}
+		}
+
+	case "douyu.com":
+		// douyu.com/123, douyu.com/room/share/123, douyu.com/topic/xxx?rid=123
+		id := last()
+		if id == "" {
+			id = last("room", "share")
+		}
+		if rid := u.Query().Get("rid"); rid != "" {
+			id = rid
+		}
+		if pathID.MatchString(id) {
+			room = Room{model.PlatformDouyu, id, "https://www.douyu.com/" + id}
+		}
+
+	case "live.douyin.com":
+		// live.douyin.com/<web_rid>
+		if id := last(); pathID.MatchString(id) {
+			room = Room{model.PlatformDouyin, id, "https://live.douyin.com/" + id}
+		}
+
+	case "douyin.com":
+		// douyin.com/user/<sec_uid>
+		if id := last("user"); pathID.MatchString(id) {
+			room = Room{model.PlatformDouyin, id, "https://www.douyin.com/user/" + id}
+		}
+
+	case "live.kuaishou.com", "kuaishou.com":
+		// live.kuaishou.com/u/<id>, www.kuaishou.com/profile/<id>
+		id := last("u")
+		if id == "" {
+			id = last("profile")
+		}
+		if pathID.MatchString(id) {
+			room = Room{model.PlatformKuaishou, id, "https://live.kuaishou.com/u/" + id}
+		}
+
+	case "cc.163.com":
+		// cc.163.com/<id>/
+		if id := last(); numericID.MatchString(id) {
+			room = Room{model.PlatformCC163, id, "https://cc.163.com/" + id}
+		}
+
+	case "weibo.com":
+		// weibo.com/l/wblive/p/show/<id>, weibo.com/u/<uid>, weibo.com/<uid>
+		id := last("l", "wblive", "p", "show")
+		if id == "" {
+			id = last("u")
+		}
+		if id == "" {
+			id = last()
+		}
+		if numericID.MatchString(id) {
+			room = Room{model.PlatformWeibo, id, "https://weibo.com/l/wblive/p/show/" + id}
+		}
+	}
+
+	if room.RoomID == "" {
+		return Room{}, fmt.Errorf("%w: %s", ErrUnsupportedURL, raw)
+	}
+	return room, nil
+}
+
+// Resolve turns a pasted room link into a streamer config, prefilling the
+// name and avatar with one status query. The ID is left to the caller.
+func Resolve(ctx context.Context, crawlers map[model.Platform]Crawler, rawURL string) (model.StreamerConfig, error) {
+	room, err := ParseRoomURL(rawURL)
+	if err != nil {
+		return model.StreamerConfig{}, err
+	}
+	c, ok := crawlers[room.Platform]
+	if !ok {
+		return model.StreamerConfig{}, fmt.Errorf("no crawler for platform: %s", room.Platform)
+	}
+
+	status, err := c.GetLiveStatus(ctx, room.RoomID)
+	if err != nil {
+		return model.StreamerConfig{}, fmt.Errorf("query %s room %s: %w", room.Platform, room.RoomID, err)
+	}
+
+	return model.StreamerConfig{
+		Name:     status.Name,
+		Platform: room.Platform,
+		RoomID:   room.RoomID,
+		Avatar:   status.Avatar,
+		LiveURL:  room.URL,
+	}, nil
+}
@@ -0,0 +1,51 @@
+package crawler
+
+import (
+	"errors"
+	"testing"
+
+	"cxtv-alerts/internal/model"
+)
+
+func TestParseRoomURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		platform model.Platform
+		roomID   string
+	}{
+		{"https://live.bilibili.com/5503838", model.PlatformBilibili, "5503838"},
+		{"live.bilibili.com/h5/123?broadcast_type=0", model.PlatformBilibili, "123"},
+		{"https://www.douyu.com/676164", model.PlatformDouyu, "676164"},
+		{"https://www.douyu.com/room/share/676164", model.PlatformDouyu, "676164"},
+		{"https://www.douyu.com/topic/abc?rid=9999", model.PlatformDouyu, "9999"},
+		{"https://live.douyin.com/646454278948", model.PlatformDouyin, "646454278948"},
+		{"https://www.douyin.com/user/MS4wLjABAAAAO6jdlMRKTeMWW109toL_W7xwiAgYKtLWJ3QC_j-wmLE?from_tab_name=main", model.PlatformDouyin, "MS4wLjABAAAAO6jdlMRKTeMWW109toL_W7xwiAgYKtLWJ3QC_j-wmLE"},
+		{"https://live.kuaishou.com/u/3xu5siisx8tk4ce", model.PlatformKuaishou, "3xu5siisx8tk4ce"},
+		{"https://www.kuaishou.com/profile/3xu5siisx8tk4ce", model.PlatformKuaishou, "3xu5siisx8tk4ce"},
+		{"https://cc.163.com/716302563/", model.PlatformCC163, "716302563"},
+		{"https://weibo.com/l/wblive/p/show/5841690305", model.PlatformWeibo, "5841690305"},
+		{"https://weibo.com/u/5841690305", model.PlatformWeibo, "5841690305"},
+	}
+	for _, tt := range tests {
+		room, err := ParseRoomURL(tt.url)
+		if err != nil {
+			t.Errorf("ParseRoomURL(%q): %v", tt.url, err)
+			continue
+		}
+		if room.Platform != tt.platform || room.RoomID != tt.roomID {
+			t.Errorf("ParseRoomURL(%q) = %s/%s, want %s/%s", tt.url, room.Platform, room.RoomID, tt.platform, tt.roomID)
+		}
+	}
+
+	for _, bad := range []string{
+		"https://example.com/123",
+		"https://live.bilibili.com/",
+		"https://live.bilibili.com/p/html/live-app-hotrank/index.html",
+		"https://www.douyin.com/video/123",
+		"https://cc.163.com/category/",
+	} {
+		if _, err := ParseRoomURL(bad); !errors.Is(err, ErrUnsupportedURL) {
+			t.Errorf("ParseRoomURL(%q) error = %v, want ErrUnsupportedURL", bad, err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package crawler
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"cxtv-alerts/internal/logging"
+)
+
+// newClient returns an HTTP client that logs every response at debug
+// level. A nil transport means http.DefaultTransport.
+func newClient(transport http.RoundTripper) *http.Client {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &http.Client{Transport: loggingTransport{transport}}
+}
+
+// loggingTransport logs the status and body size of responses with the
+// logger of the request's context, so the lines carry the streamer being
+// queried. The size is only known once the body has been closed.
+type loggingTransport struct {
+	base http.RoundTripper
+}
+
+func (t loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	logger := logging.FromContext(req.Context())
+	if !logger.Enabled(req.Context(), slog.LevelDebug) {
+		return t.base.RoundTrip(req)
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		logger.Debug("Crawler request failed",
+			"url", req.URL.Redacted(),
+			"duration", time.Since(start),
+			"error", err)
+		return nil, err
+	}
+	resp.Body = &loggedBody{
+		ReadCloser: resp.Body,
+		done: func(n int64) {
+			logger.Debug("Crawler response",
+				"url", req.URL.Redacted(),
+				"status", resp.StatusCode,
+				"bytes", n,
+				"duration", time.Since(start))
+		},
+	}
+	return resp, nil
+}
+
+type loggedBody struct {
+	io.ReadCloser
+	n    int64
+	done func(n int64)
+}
+
+func (b *loggedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+func (b *loggedBody) Close() error {
+	if b.done != nil {
+		b.done(b.n)
+		b.done = nil
+	}
+	return b.ReadCloser.Close()
+}
@@ -1,12 +1,11 @@
 package crawler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"regexp"
-	"time"
 
 	"cxtv-alerts/internal/model"
 )
@@ -17,9 +16,7 @@ type WeiboCrawler struct {
 
 func NewWeiboCrawler() *WeiboCrawler {
 	return &WeiboCrawler{
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		client: newClient(nil),
 	}
 }
 
@@ -27,29 +24,32 @@ func (c *WeiboCrawler) Platform() model.Platform {
 	return model.PlatformWeibo
 }
 
-func (c *WeiboCrawler) GetLiveStatus(roomID string) (*model.Streamer, error) {
+func (c *WeiboCrawler) GetLiveStatus(ctx context.Context, roomID string) (*model.Streamer, error) {
 	// Weibo live room URL
 	url := fmt.Sprintf("https://weibo.com/l/wblive/p/show/%s", roomID)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
 	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
 
-	resp, err := c.client.Do(req)
+	resp, err := do(c.client, req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readBody(resp)
 	if err != nil {
 		return nil, err
 	}
 
 	html := string(body)
+	if err := checkBlocked(resp, html); err != nil {
+		return nil, err
+	}
 
 	streamer := &model.Streamer{
 		Platform: model.PlatformWeibo,
@@ -89,11 +89,11 @@ func (c *WeiboCrawler) GetLiveStatus(roomID string) (*model.Streamer, error) {
 
 	// Extract title from page
 	if streamer.Title == "" {
-		titleRe := regexp.MustCompile(`<title>([^<]+)</title>`)
-		if matches := titleRe.FindStringSubmatch(html); len(matches) > 1 {
-			streamer.Title = matches[1]
-		}
+		streamer.Title = pageTitle(html)
 	}
 
+	if streamer.Title == "" && streamer.Name == "" {
+		return nil, errNoRoomData
+	}
 	return streamer, nil
 }
@@ -1,7 +1,10 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 
 	"cxtv-alerts/internal/model"
@@ -43,6 +46,20 @@ func (db *DB) migrate() error {
 	CREATE INDEX IF NOT EXISTS idx_live_sessions_streamer ON live_sessions(streamer_id);
 	CREATE INDEX IF NOT EXISTS idx_live_sessions_start_time ON live_sessions(start_time);
 
+	CREATE TABLE IF NOT EXISTS session_titles (
+		session_id INTEGER NOT NULL,
+		title TEXT NOT NULL,
+		changed_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_session_titles_session ON session_titles(session_id, changed_at);
+
+	CREATE TABLE IF NOT EXISTS viewer_samples (
+		session_id INTEGER NOT NULL,
+		sample_time DATETIME NOT NULL,
+		viewer_count INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_viewer_samples_session ON viewer_samples(session_id, sample_time);
+
 	CREATE TABLE IF NOT EXISTS streamer_status (
 		streamer_id TEXT PRIMARY KEY,
 		last_query_time DATETIME,
@@ -50,6 +67,24 @@ func (db *DB) migrate() error {
 		title TEXT,
 		viewer_count INTEGER DEFAULT 0
 	);
+
+	CREATE TABLE IF NOT EXISTS telegram_subscriptions (
+		chat_id INTEGER NOT NULL,
+		streamer_id TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (chat_id, streamer_id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_telegram_subscriptions_streamer ON telegram_subscriptions(streamer_id);
+
+	CREATE TABLE IF NOT EXISTS push_subscriptions (
+		endpoint TEXT NOT NULL,
+		streamer_id TEXT NOT NULL,
+		p256dh TEXT NOT NULL,
+		auth TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (endpoint, streamer_id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_push_subscriptions_streamer ON push_subscriptions(streamer_id);
 	`
 	if _, err := db.conn.Exec(query); err != nil {
 		return err
@@ -61,6 +96,11 @@ func (db *DB) migrate() error {
 		"ALTER TABLE streamer_status ADD COLUMN avatar_url TEXT",
 		"ALTER TABLE streamer_status ADD COLUMN avatar_local TEXT",
 		"ALTER TABLE streamer_status ADD COLUMN avatar_updated DATETIME",
+		"ALTER TABLE streamer_status ADD COLUMN last_success_time DATETIME",
+		"ALTER TABLE live_sessions ADD COLUMN end_reason TEXT",
+		"ALTER TABLE streamer_status ADD COLUMN last_error_kind TEXT",
+		// Backfill rows written before last_success_time existed
+		"UPDATE streamer_status SET last_success_time = last_query_time WHERE last_success_time IS NULL AND COALESCE(last_query_failed, 0) = 0",
 	}
 
 	for _, m := range migrations {
@@ -75,27 +115,123 @@ func (db *DB) Close() error {
 	return db.conn.Close()
 }
 
+// Ping checks that the database file can still be read
+func (db *DB) Ping(ctx context.Context) error {
+	var n int
+	return db.conn.QueryRowContext(ctx, "SELECT count(*) FROM sqlite_master").Scan(&n)
+}
+
+// IntegrityCheck runs SQLite's integrity check and returns its findings as
+// an error
+func (db *DB) IntegrityCheck() error {
+	rows, err := db.conn.Query("PRAGMA integrity_check")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var msg string
+		if err := rows.Scan(&msg); err != nil {
+			return err
+		}
+		if msg != "ok" {
+			problems = append(problems, msg)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("integrity check failed: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Vacuum rebuilds the database file, reclaiming space of deleted rows
+func (db *DB) Vacuum() error {
+	_, err := db.conn.Exec("VACUUM")
+	return err
+}
+
 // StartSession creates a new live session record
 func (db *DB) StartSession(streamerID string, platform model.Platform, roomID, title string) (int64, error) {
+	now := time.Now()
 	result, err := db.conn.Exec(
 		"INSERT INTO live_sessions (streamer_id, platform, room_id, title, start_time) VALUES (?, ?, ?, ?, ?)",
-		streamerID, platform, roomID, title, time.Now(),
+		streamerID, platform, roomID, title, now,
 	)
 	if err != nil {
 		return 0, err
 	}
-	return result.LastInsertId()
+	sessionID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	// The opening title starts the session's title history
+	if _, err := db.conn.Exec(
+		"INSERT INTO session_titles (session_id, title, changed_at) VALUES (?, ?, ?)",
+		sessionID, title, now,
+	); err != nil {
+		return sessionID, err
+	}
+	return sessionID, nil
 }
 
-// EndSession marks a session as ended
-func (db *DB) EndSession(sessionID int64) error {
+// AddSessionTitle records a title change during a session
+func (db *DB) AddSessionTitle(sessionID int64, title string) error {
 	_, err := db.conn.Exec(
-		"UPDATE live_sessions SET end_time = ? WHERE id = ?",
-		time.Now(), sessionID,
+		"INSERT INTO session_titles (session_id, title, changed_at) VALUES (?, ?, ?)",
+		sessionID, title, time.Now(),
 	)
 	return err
 }
 
+// EndSession marks a session as ended at the given time
+func (db *DB) EndSession(sessionID int64, endTime time.Time, reason string) error {
+	_, err := db.conn.Exec(
+		"UPDATE live_sessions SET end_time = ?, end_reason = ? WHERE id = ?",
+		endTime, reason, sessionID,
+	)
+	return err
+}
+
+// ReopenSession clears the end time of a session so it continues
+func (db *DB) ReopenSession(sessionID int64) error {
+	_, err := db.conn.Exec(
+		"UPDATE live_sessions SET end_time = NULL, end_reason = NULL WHERE id = ?",
+		sessionID,
+	)
+	return err
+}
+
+// GetLastSession returns the most recent session for a streamer (if any)
+func (db *DB) GetLastSession(streamerID string) (*model.LiveSession, error) {
+	row := db.conn.QueryRow(
+		"SELECT id, streamer_id, platform, room_id, title, start_time, end_time FROM live_sessions WHERE streamer_id = ? ORDER BY start_time DESC LIMIT 1",
+		streamerID,
+	)
+
+	var session model.LiveSession
+	var platform string
+	var endTime sql.NullTime
+	err := row.Scan(&session.ID, &session.StreamerID, &platform, &session.RoomID, &session.Title, &session.StartTime, &endTime)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	session.Platform = model.Platform(platform)
+	if endTime.Valid {
+		session.EndTime = &endTime.Time
+		session.Duration = int64(endTime.Time.Sub(session.StartTime).Seconds())
+	}
+	return &session, nil
+}
+
 // GetActiveSession returns the current active session for a streamer (if any)
 func (db *DB) GetActiveSession(streamerID string) (*model.LiveSession, error) {
 	row := db.conn.QueryRow(
@@ -116,12 +252,98 @@ func (db *DB) GetActiveSession(streamerID string) (*model.LiveSession, error) {
 	return &session, nil
 }
 
+// AddViewerSample records the viewer count of a live session
+func (db *DB) AddViewerSample(sessionID int64, viewerCount int64) error {
+	_, err := db.conn.Exec(
+		"INSERT INTO viewer_samples (session_id, sample_time, viewer_count) VALUES (?, ?, ?)",
+		sessionID, time.Now(), viewerCount,
+	)
+	return err
+}
+
+// GetViewerSamples returns the viewer count series of a session
+func (db *DB) GetViewerSamples(sessionID int64) ([]model.ViewerSample, error) {
+	rows, err := db.conn.Query(
+		"SELECT sample_time, viewer_count FROM viewer_samples WHERE session_id = ? ORDER BY sample_time",
+		sessionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	samples := []model.ViewerSample{}
+	for rows.Next() {
+		var sample model.ViewerSample
+		if err := rows.Scan(&sample.Time, &sample.Viewers); err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	}
+	return samples, rows.Err()
+}
+
+// sessionColumns selects a live session with its viewer statistics, in the
+// order read by scanSession
+const sessionColumns = `id, streamer_id, platform, room_id, title, start_time, end_time, COALESCE(end_reason, ''),
+	COALESCE((SELECT MAX(viewer_count) FROM viewer_samples WHERE session_id = live_sessions.id), 0),
+	COALESCE((SELECT CAST(AVG(viewer_count) AS INTEGER) FROM viewer_samples WHERE session_id = live_sessions.id), 0)`
+
+func scanSession(row interface{ Scan(...any) error }) (*model.LiveSession, error) {
+	var s model.LiveSession
+	var platform string
+	var endTime sql.NullTime
+	if err := row.Scan(&s.ID, &s.StreamerID, &platform, &s.RoomID, &s.Title, &s.StartTime, &endTime, &s.EndReason, &s.PeakViewers, &s.AvgViewers); err != nil {
+		return nil, err
+	}
+	s.Platform = model.Platform(platform)
+	if endTime.Valid {
+		s.EndTime = &endTime.Time
+		s.Duration = int64(endTime.Time.Sub(s.StartTime).Seconds())
+	}
+	return &s, nil
+}
+
+// GetSession returns a single session with its title history (nil if not found)
+func (db *DB) GetSession(sessionID int64) (*model.LiveSession, error) {
+	row := db.conn.QueryRow("SELECT "+sessionColumns+" FROM live_sessions WHERE id = ?", sessionID)
+	session, err := scanSession(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []model.LiveSession{*session}
+	if err := db.loadSessionTitles(sessions); err != nil {
+		return nil, err
+	}
+	return &sessions[0], nil
+}
+
 // GetHistory returns live session history for a streamer
 func (db *DB) GetHistory(streamerID string, limit int) ([]model.LiveSession, error) {
-	rows, err := db.conn.Query(
-		"SELECT id, streamer_id, platform, room_id, title, start_time, end_time FROM live_sessions WHERE streamer_id = ? ORDER BY start_time DESC LIMIT ?",
+	return db.querySessions(
+		"SELECT "+sessionColumns+" FROM live_sessions WHERE streamer_id = ? ORDER BY start_time DESC LIMIT ?",
 		streamerID, limit,
 	)
+}
+
+// GetSessions returns all sessions started since the given time, oldest
+// first, optionally only those of one streamer
+func (db *DB) GetSessions(streamerID string, since time.Time) ([]model.LiveSession, error) {
+	query := "SELECT " + sessionColumns + " FROM live_sessions WHERE start_time >= ?"
+	args := []any{since}
+	if streamerID != "" {
+		query += " AND streamer_id = ?"
+		args = append(args, streamerID)
+	}
+	return db.querySessions(query+" ORDER BY start_time", args...)
+}
+
+func (db *DB) querySessions(query string, args ...any) ([]model.LiveSession, error) {
+	rows, err := db.conn.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -129,22 +351,67 @@ func (db *DB) GetHistory(streamerID string, limit int) ([]model.LiveSession, err
 
 	var sessions []model.LiveSession
 	for rows.Next() {
-		var s model.LiveSession
-		var platform string
-		var endTime sql.NullTime
-		if err := rows.Scan(&s.ID, &s.StreamerID, &platform, &s.RoomID, &s.Title, &s.StartTime, &endTime); err != nil {
+		s, err := scanSession(rows)
+		if err != nil {
 			return nil, err
 		}
-		s.Platform = model.Platform(platform)
-		if endTime.Valid {
-			s.EndTime = &endTime.Time
-			s.Duration = int64(endTime.Time.Sub(s.StartTime).Seconds())
-		}
-		sessions = append(sessions, s)
+		sessions = append(sessions, *s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := db.loadSessionTitles(sessions); err != nil {
+		return nil, err
 	}
 	return sessions, nil
 }
 
+// titleBatchSize keeps IN lists below SQLite's bound parameter limit
+const titleBatchSize = 500
+
+// loadSessionTitles fills in the title history of the given sessions
+func (db *DB) loadSessionTitles(sessions []model.LiveSession) error {
+	for len(sessions) > titleBatchSize {
+		if err := db.loadSessionTitles(sessions[:titleBatchSize]); err != nil {
+			return err
+		}
+		sessions = sessions[titleBatchSize:]
+	}
+	if len(sessions) == 0 {
+		return nil
+	}
+
+	index := make(map[int64]int, len(sessions))
+	placeholders := make([]string, len(sessions))
+	args := make([]any, len(sessions))
+	for i, s := range sessions {
+		index[s.ID] = i
+		placeholders[i] = "?"
+		args[i] = s.ID
+	}
+
+	rows, err := db.conn.Query(
+		"SELECT session_id, title, changed_at FROM session_titles WHERE session_id IN ("+strings.Join(placeholders, ",")+") ORDER BY changed_at",
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sessionID int64
+		var t model.SessionTitle
+		if err := rows.Scan(&sessionID, &t.Title, &t.ChangedAt); err != nil {
+			return err
+		}
+		i := index[sessionID]
+		sessions[i].Titles = append(sessions[i].Titles, t)
+	}
+	return rows.Err()
+}
+
 // GetStats returns statistics for a streamer
 func (db *DB) GetStats(streamerID string) (*model.StreamerStats, error) {
 	stats := &model.StreamerStats{StreamerID: streamerID}
@@ -191,10 +458,33 @@ func (db *DB) GetStats(streamerID string) (*model.StreamerStats, error) {
 	return stats, nil
 }
 
-// GetLastQueryTime returns the last query time for a streamer
-func (db *DB) GetLastQueryTime(streamerID string) (*time.Time, error) {
+// GetSessionStarts returns the start times of a streamer's latest sessions,
+// newest first
+func (db *DB) GetSessionStarts(streamerID string, limit int) ([]time.Time, error) {
+	rows, err := db.conn.Query(
+		"SELECT start_time FROM live_sessions WHERE streamer_id = ? ORDER BY start_time DESC LIMIT ?",
+		streamerID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var starts []time.Time
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		starts = append(starts, t)
+	}
+	return starts, rows.Err()
+}
+
+// GetLastSuccessTime returns the time of the last successful query for a streamer
+func (db *DB) GetLastSuccessTime(streamerID string) (*time.Time, error) {
 	row := db.conn.QueryRow(
-		"SELECT last_query_time FROM streamer_status WHERE streamer_id = ?",
+		"SELECT last_success_time FROM streamer_status WHERE streamer_id = ?",
 		streamerID,
 	)
 	var lastTime sql.NullTime
@@ -211,45 +501,71 @@ func (db *DB) GetLastQueryTime(streamerID string) (*time.Time, error) {
 	return &lastTime.Time, nil
 }
 
-// UpdateStreamerStatus updates the streamer's last query time and status
-func (db *DB) UpdateStreamerStatus(streamerID string, isLive bool, title string, viewerCount int64, failed bool) error {
+// UpdateStreamerStatus updates the streamer's last query time and status.
+// errKind is the kind of error the query failed with, empty on success.
+func (db *DB) UpdateStreamerStatus(streamerID string, isLive bool, title string, viewerCount int64, errKind string) error {
 	failedInt := 0
-	if failed {
+	now := time.Now()
+	lastSuccess := &now
+	if errKind != "" {
 		failedInt = 1
+		lastSuccess = nil
 	}
 	_, err := db.conn.Exec(`
-		INSERT INTO streamer_status (streamer_id, last_query_time, last_query_failed, is_live, title, viewer_count)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO streamer_status (streamer_id, last_query_time, last_query_failed, last_error_kind, last_success_time, is_live, title, viewer_count)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(streamer_id) DO UPDATE SET
 			last_query_time = excluded.last_query_time,
 			last_query_failed = excluded.last_query_failed,
+			last_error_kind = excluded.last_error_kind,
+			last_success_time = COALESCE(excluded.last_success_time, streamer_status.last_success_time),
+			is_live = excluded.is_live,
+			title = excluded.title,
+			viewer_count = excluded.viewer_count
+	`, streamerID, now, failedInt, errKind, lastSuccess, isLive, title, viewerCount)
+	return err
+}
+
+// SaveStreamerState stores a streamer's live state without touching its
+// query times, since no query is involved
+func (db *DB) SaveStreamerState(streamerID string, isLive bool, title string, viewerCount int64) error {
+	_, err := db.conn.Exec(`
+		INSERT INTO streamer_status (streamer_id, is_live, title, viewer_count)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(streamer_id) DO UPDATE SET
 			is_live = excluded.is_live,
 			title = excluded.title,
 			viewer_count = excluded.viewer_count
-	`, streamerID, time.Now(), failedInt, isLive, title, viewerCount)
+	`, streamerID, isLive, title, viewerCount)
 	return err
 }
 
-// GetStreamerStatus returns the cached status for a streamer
-func (db *DB) GetStreamerStatus(streamerID string) (lastQueryTime *time.Time, lastQueryFailed bool, isLive bool, title string, viewerCount int64, avatarLocal string, err error) {
+// GetStreamerStatus returns the cached status for a streamer. lastError is
+// the kind of error the last query failed with, "unknown" for failures
+// recorded before kinds were stored, and empty if it succeeded.
+func (db *DB) GetStreamerStatus(streamerID string) (lastQueryTime *time.Time, lastError string, isLive bool, title string, viewerCount int64, avatarLocal string, err error) {
 	row := db.conn.QueryRow(
-		"SELECT last_query_time, COALESCE(last_query_failed, 0), is_live, title, viewer_count, COALESCE(avatar_local, '') FROM streamer_status WHERE streamer_id = ?",
+		"SELECT last_query_time, COALESCE(last_query_failed, 0), COALESCE(last_error_kind, ''), is_live, title, viewer_count, COALESCE(avatar_local, '') FROM streamer_status WHERE streamer_id = ?",
 		streamerID,
 	)
 	var lastTime sql.NullTime
 	var failedInt, liveInt int
 	var titleNull sql.NullString
-	err = row.Scan(&lastTime, &failedInt, &liveInt, &titleNull, &viewerCount, &avatarLocal)
+	err = row.Scan(&lastTime, &failedInt, &lastError, &liveInt, &titleNull, &viewerCount, &avatarLocal)
 	if err == sql.ErrNoRows {
-		return nil, false, false, "", 0, "", nil
+		return nil, "", false, "", 0, "", nil
 	}
 	if err != nil {
-		return nil, false, false, "", 0, "", err
+		return nil, "", false, "", 0, "", err
 	}
 	if lastTime.Valid {
 		lastQueryTime = &lastTime.Time
 	}
-	lastQueryFailed = failedInt == 1
+	if failedInt == 0 {
+		lastError = ""
+	} else if lastError == "" {
+		lastError = "unknown"
+	}
 	isLive = liveInt == 1
 	if titleNull.Valid {
 		title = titleNull.String
@@ -289,3 +605,122 @@ func (db *DB) UpdateAvatar(streamerID, avatarURL, avatarLocal string) error {
 	`, streamerID, avatarURL, avatarLocal, time.Now())
 	return err
 }
+
+// AddTelegramSubscription subscribes a chat to a streamer's live notifications
+func (db *DB) AddTelegramSubscription(chatID int64, streamerID string) error {
+	_, err := db.conn.Exec(
+		"INSERT OR IGNORE INTO telegram_subscriptions (chat_id, streamer_id) VALUES (?, ?)",
+		chatID, streamerID,
+	)
+	return err
+}
+
+// RemoveTelegramSubscription removes a single subscription of a chat
+func (db *DB) RemoveTelegramSubscription(chatID int64, streamerID string) error {
+	_, err := db.conn.Exec(
+		"DELETE FROM telegram_subscriptions WHERE chat_id = ? AND streamer_id = ?",
+		chatID, streamerID,
+	)
+	return err
+}
+
+// RemoveAllTelegramSubscriptions removes every subscription of a chat
+func (db *DB) RemoveAllTelegramSubscriptions(chatID int64) error {
+	_, err := db.conn.Exec("DELETE FROM telegram_subscriptions WHERE chat_id = ?", chatID)
+	return err
+}
+
+// GetTelegramSubscriptions returns the streamer IDs a chat is subscribed to
+func (db *DB) GetTelegramSubscriptions(chatID int64) ([]string, error) {
+	rows, err := db.conn.Query(
+		"SELECT streamer_id FROM telegram_subscriptions WHERE chat_id = ? ORDER BY created_at",
+		chatID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetTelegramSubscribers returns the chats subscribed to a streamer
+func (db *DB) GetTelegramSubscribers(streamerID string) ([]int64, error) {
+	rows, err := db.conn.Query(
+		"SELECT chat_id FROM telegram_subscriptions WHERE streamer_id = ?",
+		streamerID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chatIDs []int64
+	for rows.Next() {
+		var chatID int64
+		if err := rows.Scan(&chatID); err != nil {
+			return nil, err
+		}
+		chatIDs = append(chatIDs, chatID)
+	}
+	return chatIDs, rows.Err()
+}
+
+// SetPushSubscription replaces the streamers a browser push subscription is
+// notified about. An empty list removes the subscription.
+func (db *DB) SetPushSubscription(sub model.PushSubscription, streamerIDs []string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM push_subscriptions WHERE endpoint = ?", sub.Endpoint); err != nil {
+		return err
+	}
+	for _, id := range streamerIDs {
+		if _, err := tx.Exec(
+			"INSERT OR IGNORE INTO push_subscriptions (endpoint, streamer_id, p256dh, auth) VALUES (?, ?, ?, ?)",
+			sub.Endpoint, id, sub.Keys.P256dh, sub.Keys.Auth,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeletePushSubscription removes a browser push subscription entirely
+func (db *DB) DeletePushSubscription(endpoint string) error {
+	_, err := db.conn.Exec("DELETE FROM push_subscriptions WHERE endpoint = ?", endpoint)
+	return err
+}
+
+// GetPushSubscriptions returns the push subscriptions for a streamer
+func (db *DB) GetPushSubscriptions(streamerID string) ([]model.PushSubscription, error) {
+	rows, err := db.conn.Query(
+		"SELECT endpoint, p256dh, auth FROM push_subscriptions WHERE streamer_id = ?",
+		streamerID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []model.PushSubscription
+	for rows.Next() {
+		var sub model.PushSubscription
+		if err := rows.Scan(&sub.Endpoint, &sub.Keys.P256dh, &sub.Keys.Auth); err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
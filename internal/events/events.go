@@ -0,0 +1,138 @@
+// Package events keeps a bounded in-memory history of streamer status events
+// and fans new events out to live subscribers such as SSE clients.
+package events
+
+import (
+	"sync"
+	"time"
+
+	"cxtv-alerts/internal/model"
+)
+
+type Type string
+
+const (
+	LiveStart   Type = "live_start"
+	LiveEnd     Type = "live_end"
+	TitleChange Type = "title_change"
+	ScanFailed  Type = "scan_failed"
+
+	// Sent when a config change adds or removes a tracked streamer
+	StreamerAdded   Type = "streamer_added"
+	StreamerRemoved Type = "streamer_removed"
+)
+
+// Event is a single status change. IDs increase monotonically within one
+// process and are used as SSE event IDs for resuming.
+type Event struct {
+	ID        uint64         `json:"id"`
+	Type      Type           `json:"type"`
+	Time      time.Time      `json:"time"`
+	Streamer  model.Streamer `json:"streamer"`
+	SessionID int64          `json:"session_id,omitempty"`
+	PrevTitle string         `json:"prev_title,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+const subscriberBuffer = 64
+
+// Hub stores the most recent events in a ring buffer and delivers new ones
+// to subscribers.
+type Hub struct {
+	mu     sync.Mutex
+	ring   []Event
+	next   int // ring index the next event is written to
+	count  int
+	lastID uint64
+	subs   map[chan Event]struct{}
+	closed bool
+}
+
+func NewHub(capacity int) *Hub {
+	return &Hub{
+		ring: make([]Event, capacity),
+		subs: make(map[chan Event]struct{}),
+	}
+}
+
+// Publish assigns the event an ID and timestamp, stores it and delivers it
+// to subscribers without blocking. A subscriber that cannot keep up is
+// disconnected; it can resume from the ring buffer with its last event ID.
+func (h *Hub) Publish(ev Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	ev.ID = h.lastID
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	h.ring[h.next] = ev
+	h.next = (h.next + 1) % len(h.ring)
+	if h.count < len(h.ring) {
+		h.count++
+	}
+
+	for ch := range h.subs {
+		select {
+		case ch <- ev:
+		default:
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe registers a new subscriber. Events after lastID that are still
+// buffered are returned as backlog; complete is false when some of them have
+// already been evicted (or lastID is unknown, e.g. after a restart), in
+// which case the subscriber should reload the full state. A lastID of 0
+// means the subscriber needs no backlog. cancel must be called when done.
+func (h *Hub) Subscribe(lastID uint64) (backlog []Event, ch <-chan Event, cancel func(), complete bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	complete = true
+	if lastID > 0 {
+		oldestID := h.lastID - uint64(h.count) + 1
+		if lastID > h.lastID || lastID+1 < oldestID {
+			complete = false
+		}
+		for i := 0; i < h.count; i++ {
+			ev := h.ring[(h.next-h.count+i+len(h.ring))%len(h.ring)]
+			if ev.ID > lastID {
+				backlog = append(backlog, ev)
+			}
+		}
+	}
+
+	c := make(chan Event, subscriberBuffer)
+	if h.closed {
+		close(c)
+	} else {
+		h.subs[c] = struct{}{}
+	}
+
+	cancel = func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[c]; ok {
+			delete(h.subs, c)
+			close(c)
+		}
+	}
+	return backlog, c, cancel, complete
+}
+
+// Close ends every subscription, current and future, so long-lived streams
+// return on shutdown. Events are still recorded.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for ch := range h.subs {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
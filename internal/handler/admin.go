@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"cxtv-alerts/internal/model"
+	"cxtv-alerts/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requireAdmin only lets requests carrying the configured admin token as a
+// Bearer token through. Without a configured token the admin API is off.
+func (h *Handler) requireAdmin(c *gin.Context) {
+	token := h.svc.AdminToken()
+	if token == "" {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"code":    1,
+			"message": "admin API is disabled",
+		})
+		return
+	}
+
+	given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+		c.Header("WWW-Authenticate", "Bearer")
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"code":    1,
+			"message": "unauthorized",
+		})
+		return
+	}
+	c.Next()
+}
+
+func (h *Handler) ListStreamerConfigs(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": h.svc.GetStreamerConfigs(),
+	})
+}
+
+func (h *Handler) CreateStreamer(c *gin.Context) {
+	var sc model.StreamerConfig
+	if err := c.ShouldBindJSON(&sc); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    1,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if err := h.svc.AddStreamer(sc); err != nil {
+		adminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code": 0,
+		"data": sc,
+	})
+}
+
+type resolveRequest struct {
+	URL string `json:"url" binding:"required"`
+}
+
+// ResolveStreamer turns a pasted room link into a streamer entry for
+// CreateStreamer, without saving it.
+func (h *Handler) ResolveStreamer(c *gin.Context) {
+	var req resolveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    1,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	sc, err := h.svc.ResolveStreamer(c.Request.Context(), req.URL)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": sc,
+	})
+}
+
+func (h *Handler) UpdateStreamer(c *gin.Context) {
+	var sc model.StreamerConfig
+	if err := c.ShouldBindJSON(&sc); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    1,
+			"message": err.Error(),
+		})
+		return
+	}
+	id := c.Param("id")
+	if sc.ID == "" {
+		sc.ID = id
+	}
+
+	if err := h.svc.UpdateStreamer(id, sc); err != nil {
+		adminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": sc,
+	})
+}
+
+func (h *Handler) DeleteStreamer(c *gin.Context) {
+	if err := h.svc.RemoveStreamer(c.Param("id")); err != nil {
+		adminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+	})
+}
+
+func adminError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrInvalidStreamer):
+		status = http.StatusBadRequest
+	case errors.Is(err, service.ErrUnknownStreamer):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrDuplicateStreamer):
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{
+		"code":    1,
+		"message": err.Error(),
+	})
+}
@@ -1,10 +1,16 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"sort"
 	"strconv"
+	"time"
 
+	"cxtv-alerts/internal/events"
+	"cxtv-alerts/internal/model"
 	"cxtv-alerts/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -18,12 +24,32 @@ func New(svc *service.Service) *Handler {
 	return &Handler{svc: svc}
 }
 
-func (h *Handler) RegisterRoutes(r *gin.Engine) {
+func (h *Handler) RegisterRoutes(r gin.IRouter) {
+	r.GET("/metrics", h.GetMetrics)
+	r.GET("/healthz", h.Healthz)
+	r.GET("/readyz", h.Readyz)
+
 	api := r.Group("/api")
 	{
 		api.GET("/streamers", h.GetStreamers)
+		api.GET("/platforms", h.GetPlatforms)
 		api.GET("/history/:id", h.GetHistory)
 		api.GET("/stats/:id", h.GetStats)
+		api.GET("/sessions/:id", h.GetSession)
+		api.GET("/sessions/:id/viewers", h.GetSessionViewers)
+		api.GET("/events", h.StreamEvents)
+		api.GET("/push/key", h.GetPushKey)
+		api.POST("/push/subscribe", h.SubscribePush)
+		api.POST("/push/unsubscribe", h.UnsubscribePush)
+	}
+
+	admin := api.Group("/admin", h.requireAdmin)
+	{
+		admin.GET("/streamers", h.ListStreamerConfigs)
+		admin.POST("/streamers", h.CreateStreamer)
+		admin.POST("/streamers/resolve", h.ResolveStreamer)
+		admin.PUT("/streamers/:id", h.UpdateStreamer)
+		admin.DELETE("/streamers/:id", h.DeleteStreamer)
 	}
 }
 
@@ -44,6 +70,14 @@ func (h *Handler) GetStreamers(c *gin.Context) {
 	})
 }
 
+// GetPlatforms returns the circuit breaker state of each platform.
+func (h *Handler) GetPlatforms(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": h.svc.GetPlatforms(),
+	})
+}
+
 func (h *Handler) GetHistory(c *gin.Context) {
 	id := c.Param("id")
 	limit := 50
@@ -86,3 +120,194 @@ func (h *Handler) GetStats(c *gin.Context) {
 		"data": stats,
 	})
 }
+
+func (h *Handler) GetSession(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    1,
+			"message": "invalid session id",
+		})
+		return
+	}
+
+	session, err := h.svc.GetSession(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    1,
+			"message": err.Error(),
+		})
+		return
+	}
+	if session == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    1,
+			"message": "session not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": session,
+	})
+}
+
+func (h *Handler) GetSessionViewers(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    1,
+			"message": "invalid session id",
+		})
+		return
+	}
+
+	samples, err := h.svc.GetViewerSamples(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    1,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": samples,
+	})
+}
+
+func (h *Handler) GetPushKey(c *gin.Context) {
+	key, err := h.svc.PushPublicKey()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code":    1,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{"public_key": key},
+	})
+}
+
+type pushSubscribeRequest struct {
+	Subscription model.PushSubscription `json:"subscription"`
+	Streamers    []string               `json:"streamers"`
+}
+
+func (h *Handler) SubscribePush(c *gin.Context) {
+	var req pushSubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    1,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if err := h.svc.SubscribePush(req.Subscription, req.Streamers); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrPushDisabled):
+			status = http.StatusServiceUnavailable
+		case errors.Is(err, service.ErrInvalidSubscription), errors.Is(err, service.ErrUnknownStreamer):
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"code":    1,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+	})
+}
+
+func (h *Handler) UnsubscribePush(c *gin.Context) {
+	var req struct {
+		Endpoint string `json:"endpoint"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Endpoint == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    1,
+			"message": "endpoint is required",
+		})
+		return
+	}
+
+	if err := h.svc.UnsubscribePush(req.Endpoint); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    1,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+	})
+}
+
+// StreamEvents streams status changes as Server-Sent Events. Reconnecting
+// clients send Last-Event-ID and receive the events they missed, or a
+// "reset" event when those are no longer buffered.
+func (h *Handler) StreamEvents(c *gin.Context) {
+	var lastID uint64
+	if v := c.GetHeader("Last-Event-ID"); v != "" {
+		lastID, _ = strconv.ParseUint(v, 10, 64)
+	}
+
+	backlog, ch, cancel, complete := h.svc.Events().Subscribe(lastID)
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // disable proxy buffering (nginx)
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	fmt.Fprint(w, "retry: 5000\n\n")
+	if !complete {
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+	for _, ev := range backlog {
+		writeEvent(w, ev)
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(25 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case ev, ok := <-ch:
+			if !ok {
+				// Dropped for falling behind or shutting down; the client
+				// reconnects and resumes
+				return
+			}
+			writeEvent(w, ev)
+			w.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			w.Flush()
+		}
+	}
+}
+
+func writeEvent(w gin.ResponseWriter, ev events.Event) {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data)
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Healthz reports whether the process is up and the database readable.
+func (h *Handler) Healthz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.svc.Ping(ctx); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"code": 1, "message": "database: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": "ok"})
+}
+
+// Readyz reports whether the scanner is keeping the status current, with a
+// per-platform summary of recent query results.
+func (h *Handler) Readyz(c *gin.Context) {
+	r := h.svc.Readiness()
+	if !r.Ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"code": 1, "message": r.Reason, "data": r})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": r})
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"log/slog"
+	"path"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// quietRoutes are polled by monitoring and only logged at debug level. They
+// are matched by their last segment, as they sit under the base path.
+var quietRoutes = map[string]bool{
+	"metrics": true,
+	"healthz": true,
+	"readyz":  true,
+}
+
+// Logger returns middleware logging each request through slog.
+func Logger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		level := slog.LevelInfo
+		if quietRoutes[path.Base(c.FullPath())] {
+			level = slog.LevelDebug
+		}
+		slog.Log(c.Request.Context(), level, "HTTP request",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", c.Writer.Status(),
+			"bytes", c.Writer.Size(),
+			"duration", time.Since(start),
+			"client_ip", c.ClientIP())
+	}
+}
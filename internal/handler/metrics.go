@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"cxtv-alerts/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+var httpBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+
+// Metrics returns middleware counting requests and their latency by route
+// pattern, so IDs in paths do not create a series each.
+func Metrics(reg *metrics.Registry) gin.HandlerFunc {
+	requests := reg.Counter("cxtv_http_requests_total",
+		"HTTP requests handled, by route and status code.", "method", "route", "status")
+	duration := reg.Histogram("cxtv_http_request_duration_seconds",
+		"Time taken to handle HTTP requests, excluding event streams.", httpBuckets, "method", "route")
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		requests.Inc(c.Request.Method, route, strconv.Itoa(c.Writer.Status()))
+		// Event streams stay open for as long as the client is connected
+		if c.Writer.Header().Get("Content-Type") != "text/event-stream" {
+			duration.Observe(time.Since(start).Seconds(), c.Request.Method, route)
+		}
+	}
+}
+
+func (h *Handler) GetMetrics(c *gin.Context) {
+	h.svc.Metrics().Handler().ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T) {
+	r := NewRegistry()
+	c := r.Counter("scans_total", "Queries sent.", "platform")
+	c.Inc("douyu")
+	c.Add(2, "bilibili")
+	c.Inc("douyu")
+
+	h := r.Histogram("latency_seconds", "Request latency.", []float64{0.5, 1}, "platform")
+	h.Observe(0.2, "douyu")
+	h.Observe(1, "douyu")
+	h.Observe(3, "douyu")
+
+	r.GaugeFunc("title", "Escaping.", []string{"name"}, func(set func(float64, ...string)) {
+		set(1, "a \"b\"\n")
+	})
+
+	var b strings.Builder
+	if _, err := r.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# HELP scans_total Queries sent.
+# TYPE scans_total counter
+scans_total{platform="bilibili"} 2
+scans_total{platform="douyu"} 2
+# HELP latency_seconds Request latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{platform="douyu",le="0.5"} 1
+latency_seconds_bucket{platform="douyu",le="1"} 2
+latency_seconds_bucket{platform="douyu",le="+Inf"} 3
+latency_seconds_sum{platform="douyu"} 4.2
+latency_seconds_count{platform="douyu"} 3
+# HELP title Escaping.
+# TYPE title gauge
+title{name="a \"b\"\n"} 1
+`
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -14,19 +14,21 @@ const (
 )
 
 type Streamer struct {
-	ID              string   `json:"id"`
-	Name            string   `json:"name"`
-	Platform        Platform `json:"platform"`
-	RoomID          string   `json:"room_id"`
-	Avatar          string   `json:"avatar"`
-	AvatarLocal     string   `json:"avatar_local,omitempty"`
-	IsLive          bool     `json:"is_live"`
-	Title           string   `json:"title"`
-	StartTime       string   `json:"start_time,omitempty"`
-	ViewerCount     int64    `json:"viewer_count,omitempty"`
-	RoomURL         string   `json:"room_url"`
-	LastQueryTime   string   `json:"last_query_time,omitempty"`
-	LastQueryFailed bool     `json:"last_query_failed,omitempty"`
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Platform      Platform `json:"platform"`
+	RoomID        string   `json:"room_id"`
+	Avatar        string   `json:"avatar"`
+	AvatarLocal   string   `json:"avatar_local,omitempty"`
+	IsLive        bool     `json:"is_live"`
+	Title         string   `json:"title"`
+	StartTime     string   `json:"start_time,omitempty"`
+	ViewerCount   int64    `json:"viewer_count,omitempty"`
+	RoomURL       string   `json:"room_url"`
+	LastQueryTime string   `json:"last_query_time,omitempty"`
+	// LastError is the kind of error the last query failed with, as
+	// returned by crawler.Kind, or empty if it succeeded.
+	LastError string `json:"last_error,omitempty"`
 }
 
 type StreamerConfig struct {
@@ -43,28 +45,97 @@ type Config struct {
 }
 
 type Settings struct {
-	ScanIntervalMinutes     int `json:"scan_interval_minutes"`
-	PlatformDelayMinSeconds int `json:"platform_delay_min_seconds"`
-	PlatformDelayMaxSeconds int `json:"platform_delay_max_seconds"`
+	ScanIntervalMinutes        int               `json:"scan_interval_minutes"`
+	PlatformDelayMinSeconds    int               `json:"platform_delay_min_seconds"`
+	PlatformDelayMaxSeconds    int               `json:"platform_delay_max_seconds"`
+	OfflineConfirmations       int               `json:"offline_confirmations,omitempty"`         // consecutive offline scans needed to end a session
+	SessionMergeGraceMinutes   int               `json:"session_merge_grace_minutes,omitempty"`   // a session restarting within this window after the previous one was confirmed offline is merged into it
+	DowntimeThresholdMinutes   int               `json:"downtime_threshold_minutes,omitempty"`    // sessions not seen for longer are closed on startup
+	RequestTimeoutSeconds      int               `json:"request_timeout_seconds,omitempty"`       // deadline of a single crawler request
+	PublicURL                  string            `json:"public_url,omitempty"`                    // used to build absolute links in notifications
+	AdminToken                 string            `json:"admin_token,omitempty"`                   // bearer token of the admin API, disabled when empty
+	LogLevel                   string            `json:"log_level,omitempty"`                     // debug, info, warn or error; overrides -log-level and applies on reload
+	LiveScanIntervalMinutes    int               `json:"live_scan_interval_minutes,omitempty"`    // while live or near an hour the streamer usually goes live
+	DormantScanIntervalMinutes int               `json:"dormant_scan_interval_minutes,omitempty"` // once the streamer has not been live for dormant_days
+	DormantDays                int               `json:"dormant_days,omitempty"`
+	BreakerThreshold           int               `json:"breaker_threshold,omitempty"`           // consecutive blocked or rate limited queries that pause a platform
+	BreakerBackoffMinutes      int               `json:"breaker_backoff_minutes,omitempty"`     // first pause, doubled on every further trip
+	BreakerMaxBackoffMinutes   int               `json:"breaker_max_backoff_minutes,omitempty"` // longest pause
+	Telegram                   *TelegramSettings `json:"telegram,omitempty"`
+	Webhooks                   []WebhookConfig   `json:"webhooks,omitempty"`
 }
 
+type TelegramSettings struct {
+	BotToken string   `json:"bot_token"`
+	APIBase  string   `json:"api_base,omitempty"` // defaults to https://api.telegram.org
+	Events   []string `json:"events,omitempty"`   // defaults to live_start
+}
+
+// WebhookConfig describes one outgoing webhook target. Streamers and
+// Platforms restrict which streamers the target is notified about; empty
+// means all. Events selects the event types to send, defaulting to
+// live_start and live_end.
+type WebhookConfig struct {
+	Name      string     `json:"name,omitempty"`
+	Type      string     `json:"type"` // discord, json, bark, serverchan, wecom, dingtalk, feishu
+	URL       string     `json:"url,omitempty"`
+	Key       string     `json:"key,omitempty"`      // bark device key, serverchan SendKey
+	Secret    string     `json:"secret,omitempty"`   // dingtalk/feishu signing secret
+	Template  string     `json:"template,omitempty"` // text/template body for the json type
+	Streamers []string   `json:"streamers,omitempty"`
+	Platforms []Platform `json:"platforms,omitempty"`
+	Events    []string   `json:"events,omitempty"`
+}
+
+// PushSubscription mirrors the JSON form of a browser PushSubscription.
+type PushSubscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// Session end reasons
+const (
+	EndReasonOffline  = "offline"
+	EndReasonDowntime = "unknown/downtime" // closed on startup after the service was down
+	EndReasonRemoved  = "removed"          // the streamer was removed from the config
+)
+
 type LiveSession struct {
-	ID         int64     `json:"id"`
-	StreamerID string    `json:"streamer_id"`
-	Platform   Platform  `json:"platform"`
-	RoomID     string    `json:"room_id"`
-	Title      string    `json:"title"`
-	StartTime  time.Time `json:"start_time"`
-	EndTime    *time.Time `json:"end_time,omitempty"`
-	Duration   int64     `json:"duration,omitempty"` // seconds
+	ID          int64          `json:"id"`
+	StreamerID  string         `json:"streamer_id"`
+	Platform    Platform       `json:"platform"`
+	RoomID      string         `json:"room_id"`
+	Title       string         `json:"title"`
+	StartTime   time.Time      `json:"start_time"`
+	EndTime     *time.Time     `json:"end_time,omitempty"`
+	EndReason   string         `json:"end_reason,omitempty"`
+	Duration    int64          `json:"duration,omitempty"` // seconds
+	PeakViewers int64          `json:"peak_viewers,omitempty"`
+	AvgViewers  int64          `json:"avg_viewers,omitempty"`
+	Titles      []SessionTitle `json:"titles,omitempty"`
+}
+
+// SessionTitle is one entry of a session's title history
+type SessionTitle struct {
+	Title     string    `json:"title"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// ViewerSample is one viewer count observation during a session
+type ViewerSample struct {
+	Time    time.Time `json:"time"`
+	Viewers int64     `json:"viewers"`
 }
 
 type StreamerStats struct {
-	StreamerID     string  `json:"streamer_id"`
-	TotalSessions  int     `json:"total_sessions"`
-	TotalDuration  int64   `json:"total_duration"`  // seconds
-	AvgDuration    int64   `json:"avg_duration"`    // seconds
-	LastLiveTime   string  `json:"last_live_time,omitempty"`
-	WeekSessions   int     `json:"week_sessions"`
-	MonthSessions  int     `json:"month_sessions"`
+	StreamerID    string `json:"streamer_id"`
+	TotalSessions int    `json:"total_sessions"`
+	TotalDuration int64  `json:"total_duration"` // seconds
+	AvgDuration   int64  `json:"avg_duration"`   // seconds
+	LastLiveTime  string `json:"last_live_time,omitempty"`
+	WeekSessions  int    `json:"week_sessions"`
+	MonthSessions int    `json:"month_sessions"`
 }
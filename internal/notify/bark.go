@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const defaultBarkServer = "https://api.day.app"
+
+// BarkNotifier pushes to an iOS device through a Bark server.
+type BarkNotifier struct {
+	name      string
+	server    string
+	deviceKey string
+}
+
+func NewBarkNotifier(name, server, deviceKey string) *BarkNotifier {
+	if server == "" {
+		server = defaultBarkServer
+	}
+	return &BarkNotifier{
+		name:      name,
+		server:    strings.TrimRight(server, "/"),
+		deviceKey: deviceKey,
+	}
+}
+
+func (b *BarkNotifier) Name() string {
+	return b.name
+}
+
+func (b *BarkNotifier) Notify(ctx context.Context, ev Event) error {
+	title, body := formatMessage(ev)
+	payload := map[string]string{
+		"device_key": b.deviceKey,
+		"title":      title,
+		"body":       body,
+		"group":      "cxtv-alerts",
+	}
+	if ev.Streamer.RoomURL != "" {
+		payload["url"] = ev.Streamer.RoomURL
+	}
+	if ev.Streamer.Avatar != "" {
+		payload["icon"] = ev.Streamer.Avatar
+	}
+
+	respBody, err := postJSON(ctx, b.server+"/push", payload)
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return err
+	}
+	if result.Code != 200 {
+		return fmt.Errorf("bark error %d: %s", result.Code, result.Message)
+	}
+	return nil
+}
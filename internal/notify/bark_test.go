@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBarkNotifier(t *testing.T) {
+	url, next := newStub(t, `{"code":200,"message":"success"}`)
+
+	n := NewBarkNotifier("bark", url, "device-key")
+	if err := n.Notify(context.Background(), testEvent()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	req := next()
+	if req.URL.Path != "/push" {
+		t.Errorf("path = %s, want /push", req.URL.Path)
+	}
+	var got map[string]string
+	req.decodeJSON(t, &got)
+	if got["device_key"] != "device-key" {
+		t.Errorf("device_key = %q", got["device_key"])
+	}
+	if got["title"] != "测试主播 开播了" {
+		t.Errorf("title = %q", got["title"])
+	}
+	if got["url"] != "https://live.bilibili.com/123" {
+		t.Errorf("url = %q", got["url"])
+	}
+}
+
+func TestBarkNotifierError(t *testing.T) {
+	url, _ := newStub(t, `{"code":400,"message":"failed to get device token"}`)
+
+	n := NewBarkNotifier("bark", url, "bad-key")
+	if err := n.Notify(context.Background(), testEvent()); err == nil {
+		t.Fatal("expected error for non-200 code")
+	}
+}
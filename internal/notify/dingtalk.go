@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DingTalkNotifier posts Markdown messages to a DingTalk (钉钉) robot. When
+// a secret is set, requests are signed as required by the robot's
+// "加签" security setting.
+type DingTalkNotifier struct {
+	name   string
+	url    string
+	secret string
+}
+
+func NewDingTalkNotifier(name, webhookURL, secret string) *DingTalkNotifier {
+	return &DingTalkNotifier{name: name, url: webhookURL, secret: secret}
+}
+
+func (d *DingTalkNotifier) Name() string {
+	return d.name
+}
+
+// dingTalkSign returns base64(HMAC-SHA256(secret, timestamp + "\n" + secret)).
+func dingTalkSign(timestamp int64, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d\n%s", timestamp, secret)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (d *DingTalkNotifier) signedURL() string {
+	if d.secret == "" {
+		return d.url
+	}
+	timestamp := time.Now().UnixMilli()
+	sep := "?"
+	if strings.Contains(d.url, "?") {
+		sep = "&"
+	}
+	return d.url + sep + "timestamp=" + strconv.FormatInt(timestamp, 10) +
+		"&sign=" + url.QueryEscape(dingTalkSign(timestamp, d.secret))
+}
+
+func (d *DingTalkNotifier) Notify(ctx context.Context, ev Event) error {
+	title, body := formatMessage(ev)
+	text := fmt.Sprintf("### %s\n\n%s", title, strings.ReplaceAll(body, "\n", "\n\n"))
+	if ev.Streamer.RoomURL != "" {
+		text += fmt.Sprintf("\n\n[打开直播间](%s)", ev.Streamer.RoomURL)
+	}
+
+	respBody, err := postJSON(ctx, d.signedURL(), map[string]any{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": title,
+			"text":  text,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return checkErrcode("dingtalk", respBody)
+}
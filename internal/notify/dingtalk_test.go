@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestDingTalkNotifierSigned(t *testing.T) {
+	const secret = "SEC000000"
+	url, next := newStub(t, `{"errcode":0,"errmsg":"ok"}`)
+
+	n := NewDingTalkNotifier("dingtalk", url+"/robot/send?access_token=token", secret)
+	if err := n.Notify(context.Background(), testEvent()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	req := next()
+	q := req.URL.Query()
+	if q.Get("access_token") != "token" {
+		t.Errorf("access_token = %q", q.Get("access_token"))
+	}
+	timestamp, err := strconv.ParseInt(q.Get("timestamp"), 10, 64)
+	if err != nil {
+		t.Fatalf("bad timestamp %q: %v", q.Get("timestamp"), err)
+	}
+	if want := dingTalkSign(timestamp, secret); q.Get("sign") != want {
+		t.Errorf("sign = %q, want %q", q.Get("sign"), want)
+	}
+
+	var got struct {
+		MsgType  string `json:"msgtype"`
+		Markdown struct {
+			Title string `json:"title"`
+			Text  string `json:"text"`
+		} `json:"markdown"`
+	}
+	req.decodeJSON(t, &got)
+	if got.MsgType != "markdown" || got.Markdown.Title != "测试主播 开播了" {
+		t.Errorf("unexpected payload: %+v", got)
+	}
+}
+
+func TestDingTalkSign(t *testing.T) {
+	// Reference value computed with the algorithm from the DingTalk docs
+	if got := dingTalkSign(1700000000000, "SECabc"); got != "jcUpW0QmtKduN03n4JqQ0PBosVjqnM8gU7fIIvsDmCM=" {
+		t.Errorf("dingTalkSign = %q", got)
+	}
+}
+
+func TestDingTalkNotifierError(t *testing.T) {
+	url, next := newStub(t, `{"errcode":310000,"errmsg":"sign not match"}`)
+
+	n := NewDingTalkNotifier("dingtalk", url+"/robot/send?access_token=token", "")
+	if err := n.Notify(context.Background(), testEvent()); err == nil {
+		t.Fatal("expected error for non-zero errcode")
+	}
+	if next().URL.Query().Has("sign") {
+		t.Error("unsigned robot should not send a sign parameter")
+	}
+}
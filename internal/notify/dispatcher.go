@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	queueSize   = 64
+	maxAttempts = 3
+	retryDelay  = 5 * time.Second
+	sendTimeout = 30 * time.Second
+)
+
+// Dispatcher fans events out to registered notifiers. Every notifier gets its
+// own buffered queue and worker goroutine, so a slow or failing channel never
+// delays the caller or the other channels.
+type Dispatcher struct {
+	mu       sync.RWMutex
+	channels []*channel
+	closed   bool
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+type channel struct {
+	notifier Notifier
+	queue    chan Event
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{done: make(chan struct{})}
+}
+
+// Register adds a notifier and starts its delivery worker.
+func (d *Dispatcher) Register(n Notifier) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+
+	ch := &channel{
+		notifier: n,
+		queue:    make(chan Event, queueSize),
+	}
+	d.channels = append(d.channels, ch)
+
+	d.wg.Add(1)
+	go d.run(ch)
+
+	slog.Info("Notifier registered", "notifier", n.Name())
+}
+
+// Dispatch queues an event for every registered notifier that accepts it. It
+// never blocks: if a notifier's queue is full the event is dropped for that
+// notifier.
+func (d *Dispatcher) Dispatch(ev Event) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return
+	}
+
+	for _, ch := range d.channels {
+		if a, ok := ch.notifier.(acceptor); ok && !a.Accept(ev) {
+			continue
+		}
+		select {
+		case ch.queue <- ev:
+		default:
+			slog.Warn("Notifier queue full, dropping event", "notifier", ch.notifier.Name(), "event", ev.Type, "streamer_id", ev.Streamer.ID)
+		}
+	}
+}
+
+// Close stops accepting events, waits for queued events to be delivered and
+// abandons pending retries.
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return
+	}
+	d.closed = true
+	close(d.done)
+	for _, ch := range d.channels {
+		close(ch.queue)
+	}
+	d.mu.Unlock()
+
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) run(ch *channel) {
+	defer d.wg.Done()
+	for ev := range ch.queue {
+		d.deliver(ch.notifier, ev)
+	}
+}
+
+func (d *Dispatcher) deliver(n Notifier, ev Event) {
+	delay := retryDelay
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		err := n.Notify(ctx, ev)
+		cancel()
+		if err == nil {
+			return
+		}
+
+		if attempt >= maxAttempts {
+			slog.Error("Notifier gave up sending event", "notifier", n.Name(), "event", ev.Type, "streamer_id", ev.Streamer.ID, "attempts", attempt, "error", err)
+			return
+		}
+		slog.Warn("Notifier failed to send event", "notifier", n.Name(), "event", ev.Type, "streamer_id", ev.Streamer.ID, "attempt", attempt, "error", err)
+
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-d.done:
+			return
+		}
+	}
+}
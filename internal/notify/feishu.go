@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// FeishuNotifier posts text messages to a Feishu/Lark (飞书) custom bot. When
+// a secret is set, requests carry the signature required by the bot's
+// "签名校验" security setting.
+type FeishuNotifier struct {
+	name   string
+	url    string
+	secret string
+}
+
+func NewFeishuNotifier(name, webhookURL, secret string) *FeishuNotifier {
+	return &FeishuNotifier{name: name, url: webhookURL, secret: secret}
+}
+
+func (f *FeishuNotifier) Name() string {
+	return f.name
+}
+
+// feishuSign returns base64(HMAC-SHA256(key: timestamp + "\n" + secret, data: "")).
+func feishuSign(timestamp int64, secret string) string {
+	mac := hmac.New(sha256.New, []byte(fmt.Sprintf("%d\n%s", timestamp, secret)))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (f *FeishuNotifier) Notify(ctx context.Context, ev Event) error {
+	title, body := formatMessage(ev)
+	text := title + "\n" + body
+	if ev.Streamer.RoomURL != "" {
+		text += "\n" + ev.Streamer.RoomURL
+	}
+	payload := map[string]any{
+		"msg_type": "text",
+		"content": map[string]string{
+			"text": text,
+		},
+	}
+	if f.secret != "" {
+		timestamp := time.Now().Unix()
+		payload["timestamp"] = strconv.FormatInt(timestamp, 10)
+		payload["sign"] = feishuSign(timestamp, f.secret)
+	}
+
+	respBody, err := postJSON(ctx, f.url, payload)
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return err
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("feishu error %d: %s", result.Code, result.Msg)
+	}
+	return nil
+}
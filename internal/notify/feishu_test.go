@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFeishuNotifierSigned(t *testing.T) {
+	const secret = "feishu-secret"
+	url, next := newStub(t, `{"code":0,"msg":"success","data":{}}`)
+
+	n := NewFeishuNotifier("feishu", url, secret)
+	if err := n.Notify(context.Background(), testEvent()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	var got struct {
+		Timestamp string `json:"timestamp"`
+		Sign      string `json:"sign"`
+		MsgType   string `json:"msg_type"`
+		Content   struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	next().decodeJSON(t, &got)
+	timestamp, err := strconv.ParseInt(got.Timestamp, 10, 64)
+	if err != nil {
+		t.Fatalf("bad timestamp %q: %v", got.Timestamp, err)
+	}
+	if want := feishuSign(timestamp, secret); got.Sign != want {
+		t.Errorf("sign = %q, want %q", got.Sign, want)
+	}
+	if got.MsgType != "text" {
+		t.Errorf("msg_type = %q", got.MsgType)
+	}
+	if !strings.HasPrefix(got.Content.Text, "测试主播 开播了") || !strings.Contains(got.Content.Text, "https://live.bilibili.com/123") {
+		t.Errorf("text = %q", got.Content.Text)
+	}
+}
+
+func TestFeishuSign(t *testing.T) {
+	// Reference value computed with the algorithm from the Feishu docs
+	if got := feishuSign(1700000000, "abc"); got != "VIS10b0EBvzzSdFnuk4tznEmK5wHaruvf/WnViv2yR4=" {
+		t.Errorf("feishuSign = %q", got)
+	}
+}
+
+func TestFeishuNotifierError(t *testing.T) {
+	url, _ := newStub(t, `{"code":19021,"msg":"sign match fail or timestamp is not within one hour from current time"}`)
+
+	n := NewFeishuNotifier("feishu", url, "")
+	if err := n.Notify(context.Background(), testEvent()); err == nil {
+		t.Fatal("expected error for non-zero code")
+	}
+}
@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"fmt"
+	"slices"
+
+	"cxtv-alerts/internal/model"
+)
+
+// Filter restricts which events a notifier receives. Empty fields match
+// everything.
+type Filter struct {
+	Streamers []string
+	Platforms []model.Platform
+	Events    []EventType
+}
+
+// EventTypes converts configured event names, falling back to defaults when
+// none are configured. Unknown names are reported as an error.
+func EventTypes(names []string, defaults ...EventType) ([]EventType, error) {
+	if len(names) == 0 {
+		return defaults, nil
+	}
+	types := make([]EventType, 0, len(names))
+	for _, name := range names {
+		t := EventType(name)
+		switch t {
+		case EventLiveStart, EventLiveEnd, EventTitleChange:
+			types = append(types, t)
+		default:
+			return nil, fmt.Errorf("unknown event type %q", name)
+		}
+	}
+	return types, nil
+}
+
+func (f Filter) Match(ev Event) bool {
+	if len(f.Events) > 0 && !slices.Contains(f.Events, ev.Type) {
+		return false
+	}
+	if len(f.Streamers) > 0 && !slices.Contains(f.Streamers, ev.Streamer.ID) {
+		return false
+	}
+	if len(f.Platforms) > 0 && !slices.Contains(f.Platforms, ev.Streamer.Platform) {
+		return false
+	}
+	return true
+}
+
+// acceptor is implemented by notifiers that only want a subset of events.
+// The dispatcher consults it before queueing so filtered events never take
+// up queue space.
+type acceptor interface {
+	Accept(ev Event) bool
+}
+
+type filtered struct {
+	Notifier
+	filter Filter
+}
+
+// Filtered wraps n so that it only receives events matching f.
+func Filtered(n Notifier, f Filter) Notifier {
+	return &filtered{Notifier: n, filter: f}
+}
+
+func (f *filtered) Accept(ev Event) bool {
+	return f.filter.Match(ev)
+}
@@ -0,0 +1,80 @@
+// Package notify delivers streamer live status transitions to external
+// channels such as chat bots and webhooks.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"cxtv-alerts/internal/model"
+)
+
+type EventType string
+
+const (
+	EventLiveStart   EventType = "live_start"
+	EventLiveEnd     EventType = "live_end"
+	EventTitleChange EventType = "title_change"
+)
+
+// Event describes a single status transition of a streamer.
+type Event struct {
+	Type      EventType      `json:"type"`
+	Streamer  model.Streamer `json:"streamer"`
+	SessionID int64          `json:"session_id,omitempty"`
+	Time      time.Time      `json:"time"`
+	PrevTitle string         `json:"prev_title,omitempty"` // title_change only
+}
+
+// Notifier sends events to one external channel. Notify may block on the
+// network; the dispatcher runs it outside of any service locks and retries
+// it when an error is returned.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, ev Event) error
+}
+
+var platformNames = map[model.Platform]string{
+	model.PlatformBilibili: "B站",
+	model.PlatformDouyu:    "斗鱼",
+	model.PlatformDouyin:   "抖音",
+	model.PlatformKuaishou: "快手",
+	model.PlatformCC163:    "网易CC",
+	model.PlatformWeibo:    "微博",
+}
+
+// PlatformName returns the display name of a platform.
+func PlatformName(p model.Platform) string {
+	if name, ok := platformNames[p]; ok {
+		return name
+	}
+	return string(p)
+}
+
+// formatMessage renders a plain text title and body for channels without
+// rich formatting. The room URL is left to the caller, since most channels
+// have a dedicated field or link syntax for it.
+func formatMessage(ev Event) (title, body string) {
+	s := ev.Streamer
+	switch ev.Type {
+	case EventLiveStart:
+		title = fmt.Sprintf("%s 开播了", s.Name)
+	case EventLiveEnd:
+		title = fmt.Sprintf("%s 下播了", s.Name)
+	case EventTitleChange:
+		title = fmt.Sprintf("%s 更换了标题", s.Name)
+	default:
+		title = fmt.Sprintf("%s: %s", s.Name, ev.Type)
+	}
+
+	lines := []string{"平台: " + PlatformName(s.Platform)}
+	if (ev.Type == EventLiveStart || ev.Type == EventTitleChange) && s.Title != "" {
+		lines = append(lines, "标题: "+s.Title)
+	}
+	if ev.Type == EventTitleChange && ev.PrevTitle != "" {
+		lines = append(lines, "原标题: "+ev.PrevTitle)
+	}
+	return title, strings.Join(lines, "\n")
+}
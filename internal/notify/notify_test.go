@@ -0,0 +1,109 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"cxtv-alerts/internal/model"
+)
+
+func testEvent() Event {
+	return Event{
+		Type: EventLiveStart,
+		Streamer: model.Streamer{
+			ID:       "bilibili_1",
+			Name:     "测试主播",
+			Platform: model.PlatformBilibili,
+			RoomID:   "123",
+			Avatar:   "https://example.com/avatar.jpg",
+			IsLive:   true,
+			Title:    "今天播点什么",
+			RoomURL:  "https://live.bilibili.com/123",
+		},
+		SessionID: 42,
+		Time:      time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+// stubRequest is a request received by a stub server.
+type stubRequest struct {
+	URL  *url.URL
+	Body []byte
+}
+
+// decodeJSON decodes the request body into v, failing the test if it is
+// not valid JSON.
+func (r stubRequest) decodeJSON(t *testing.T, v any) {
+	t.Helper()
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		t.Fatalf("decode body %q: %v", r.Body, err)
+	}
+}
+
+// newStub starts a server answering every request with response. It returns
+// the server's URL and a function that waits for the next request received.
+// Requests are handed over on a channel, so all assertions run on the test
+// goroutine.
+func newStub(t *testing.T, response string) (string, func() stubRequest) {
+	t.Helper()
+	requests := make(chan stubRequest, 16)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		requests <- stubRequest{URL: r.URL, Body: body}
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(srv.Close)
+
+	next := func() stubRequest {
+		t.Helper()
+		select {
+		case r := <-requests:
+			return r
+		case <-time.After(5 * time.Second):
+			t.Fatal("stub server received no request")
+			return stubRequest{}
+		}
+	}
+	return srv.URL, next
+}
+
+func TestFormatMessage(t *testing.T) {
+	title, body := formatMessage(testEvent())
+	if title != "测试主播 开播了" {
+		t.Errorf("title = %q", title)
+	}
+	if !strings.Contains(body, "平台: B站") || !strings.Contains(body, "标题: 今天播点什么") {
+		t.Errorf("body = %q", body)
+	}
+
+	ev := testEvent()
+	ev.Type = EventLiveEnd
+	title, body = formatMessage(ev)
+	if title != "测试主播 下播了" {
+		t.Errorf("title = %q", title)
+	}
+	if strings.Contains(body, "标题") {
+		t.Errorf("live end body should not contain the title: %q", body)
+	}
+
+	ev = testEvent()
+	ev.Type = EventTitleChange
+	ev.PrevTitle = "旧标题"
+	title, body = formatMessage(ev)
+	if title != "测试主播 更换了标题" {
+		t.Errorf("title = %q", title)
+	}
+	if !strings.Contains(body, "标题: 今天播点什么") || !strings.Contains(body, "原标题: 旧标题") {
+		t.Errorf("body = %q", body)
+	}
+}
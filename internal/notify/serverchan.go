@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const defaultServerChanServer = "https://sctapi.ftqq.com"
+
+// sctp keys belong to ServerChan³ and are sent to a per-user host.
+var serverChan3KeyRe = regexp.MustCompile(`^sctp(\d+)t`)
+
+// ServerChanNotifier sends messages through ServerChan Turbo (Server酱).
+type ServerChanNotifier struct {
+	name    string
+	server  string
+	sendKey string
+}
+
+// NewServerChanNotifier creates a ServerChan notifier. server overrides the
+// API host and is normally left empty.
+func NewServerChanNotifier(name, server, sendKey string) *ServerChanNotifier {
+	return &ServerChanNotifier{
+		name:    name,
+		server:  strings.TrimRight(server, "/"),
+		sendKey: sendKey,
+	}
+}
+
+func (s *ServerChanNotifier) Name() string {
+	return s.name
+}
+
+func (s *ServerChanNotifier) endpoint() string {
+	if s.server != "" {
+		return fmt.Sprintf("%s/%s.send", s.server, s.sendKey)
+	}
+	if m := serverChan3KeyRe.FindStringSubmatch(s.sendKey); m != nil {
+		return fmt.Sprintf("https://%s.push.ft07.com/send/%s.send", m[1], s.sendKey)
+	}
+	return fmt.Sprintf("%s/%s.send", defaultServerChanServer, s.sendKey)
+}
+
+func (s *ServerChanNotifier) Notify(ctx context.Context, ev Event) error {
+	title, body := formatMessage(ev)
+	if ev.Streamer.RoomURL != "" {
+		body += fmt.Sprintf("\n[打开直播间](%s)", ev.Streamer.RoomURL)
+	}
+	form := url.Values{
+		"title": {title},
+		// desp is Markdown, which needs blank lines between paragraphs
+		"desp": {strings.ReplaceAll(body, "\n", "\n\n")},
+	}
+
+	respBody, err := post(ctx, s.endpoint(), "application/x-www-form-urlencoded", []byte(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return err
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("serverchan error %d: %s", result.Code, result.Message)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestServerChanNotifier(t *testing.T) {
+	srvURL, next := newStub(t, `{"code":0,"message":""}`)
+
+	n := NewServerChanNotifier("serverchan", srvURL, "SCT123")
+	if err := n.Notify(context.Background(), testEvent()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	req := next()
+	if req.URL.Path != "/SCT123.send" {
+		t.Errorf("path = %s, want /SCT123.send", req.URL.Path)
+	}
+	form, err := url.ParseQuery(string(req.Body))
+	if err != nil {
+		t.Fatalf("parse form: %v", err)
+	}
+	if title := form.Get("title"); title != "测试主播 开播了" {
+		t.Errorf("title = %q", title)
+	}
+	if desp := form.Get("desp"); !strings.Contains(desp, "(https://live.bilibili.com/123)") {
+		t.Errorf("desp missing room link: %q", desp)
+	}
+}
+
+func TestServerChanEndpoint(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"SCT123", "https://sctapi.ftqq.com/SCT123.send"},
+		{"sctp42tabc", "https://42.push.ft07.com/send/sctp42tabc.send"},
+	}
+	for _, tt := range tests {
+		if got := NewServerChanNotifier("sc", "", tt.key).endpoint(); got != tt.want {
+			t.Errorf("endpoint(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestServerChanNotifierError(t *testing.T) {
+	srvURL, _ := newStub(t, `{"code":40001,"message":"bad pushkey"}`)
+
+	n := NewServerChanNotifier("serverchan", srvURL, "SCT123")
+	if err := n.Notify(context.Background(), testEvent()); err == nil {
+		t.Fatal("expected error for non-zero code")
+	}
+}
@@ -0,0 +1,317 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"cxtv-alerts/internal/model"
+)
+
+const (
+	defaultTelegramAPIBase = "https://api.telegram.org"
+	telegramPollTimeout    = 30 // seconds
+)
+
+// TelegramStore persists which chats are subscribed to which streamers.
+type TelegramStore interface {
+	AddTelegramSubscription(chatID int64, streamerID string) error
+	RemoveTelegramSubscription(chatID int64, streamerID string) error
+	RemoveAllTelegramSubscriptions(chatID int64) error
+	GetTelegramSubscriptions(chatID int64) ([]string, error)
+	GetTelegramSubscribers(streamerID string) ([]int64, error)
+}
+
+// StreamerLookup returns the current state of a tracked streamer.
+type StreamerLookup func(id string) (model.Streamer, bool)
+
+// TelegramNotifier sends live start (and optionally title change) messages
+// to subscribed chats and serves the /subscribe, /unsubscribe and /list bot
+// commands via long polling.
+type TelegramNotifier struct {
+	token   string
+	apiBase string
+	client  *http.Client
+	store   TelegramStore
+	lookup  StreamerLookup
+}
+
+func NewTelegramNotifier(token, apiBase string, store TelegramStore, lookup StreamerLookup) *TelegramNotifier {
+	if apiBase == "" {
+		apiBase = defaultTelegramAPIBase
+	}
+	return &TelegramNotifier{
+		token:   token,
+		apiBase: strings.TrimRight(apiBase, "/"),
+		client:  &http.Client{},
+		store:   store,
+		lookup:  lookup,
+	}
+}
+
+func (t *TelegramNotifier) Name() string {
+	return "telegram"
+}
+
+func (t *TelegramNotifier) Notify(ctx context.Context, ev Event) error {
+	var text string
+	switch ev.Type {
+	case EventLiveStart:
+		text = formatTelegramLiveStart(ev.Streamer)
+	case EventTitleChange:
+		text = formatTelegramTitleChange(ev)
+	default:
+		return nil
+	}
+
+	chatIDs, err := t.store.GetTelegramSubscribers(ev.Streamer.ID)
+	if err != nil {
+		return err
+	}
+	if len(chatIDs) == 0 {
+		return nil
+	}
+
+	// Only report an error (and so trigger a retry) when no chat got the
+	// message, otherwise a retry would send duplicates.
+	var lastErr error
+	sent := 0
+	for _, chatID := range chatIDs {
+		if err := t.sendMessage(ctx, chatID, text); err != nil {
+			slog.Warn("Telegram: failed to notify chat", "chat_id", chatID, "streamer_id", ev.Streamer.ID, "error", err)
+			lastErr = err
+			continue
+		}
+		sent++
+	}
+	if sent == 0 {
+		return lastErr
+	}
+	return nil
+}
+
+func formatTelegramLiveStart(s model.Streamer) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🔴 <b>%s</b> 开播了\n", html.EscapeString(s.Name))
+	fmt.Fprintf(&b, "平台: %s\n", html.EscapeString(PlatformName(s.Platform)))
+	if s.Title != "" {
+		fmt.Fprintf(&b, "标题: %s\n", html.EscapeString(s.Title))
+	}
+	if s.RoomURL != "" {
+		b.WriteString(html.EscapeString(s.RoomURL))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func formatTelegramTitleChange(ev Event) string {
+	s := ev.Streamer
+	var b strings.Builder
+	fmt.Fprintf(&b, "✏️ <b>%s</b> 更换了标题\n", html.EscapeString(s.Name))
+	fmt.Fprintf(&b, "标题: %s\n", html.EscapeString(s.Title))
+	if ev.PrevTitle != "" {
+		fmt.Fprintf(&b, "原标题: %s\n", html.EscapeString(ev.PrevTitle))
+	}
+	if s.RoomURL != "" {
+		b.WriteString(html.EscapeString(s.RoomURL))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+type telegramResponse struct {
+	OK          bool            `json:"ok"`
+	Description string          `json:"description"`
+	Result      json.RawMessage `json:"result"`
+}
+
+type telegramUpdate struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Text string `json:"text"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+	} `json:"message"`
+}
+
+func (t *TelegramNotifier) call(ctx context.Context, method string, payload any, out any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/bot%s/%s", t.apiBase, t.token, method)
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		// Do not leak the bot token embedded in the request URL
+		if urlErr, ok := err.(*url.Error); ok {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result telegramResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("telegram %s: HTTP %d: %w", method, resp.StatusCode, err)
+	}
+	if !result.OK {
+		return fmt.Errorf("telegram %s: %s", method, result.Description)
+	}
+	if out != nil {
+		return json.Unmarshal(result.Result, out)
+	}
+	return nil
+}
+
+func (t *TelegramNotifier) sendMessage(ctx context.Context, chatID int64, text string) error {
+	return t.call(ctx, "sendMessage", map[string]any{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	}, nil)
+}
+
+// Run long-polls the Bot API for commands until ctx is cancelled.
+func (t *TelegramNotifier) Run(ctx context.Context) {
+	slog.Info("Telegram bot polling started")
+	var offset int64
+
+	for {
+		var updates []telegramUpdate
+		pollCtx, cancel := context.WithTimeout(ctx, (telegramPollTimeout+10)*time.Second)
+		err := t.call(pollCtx, "getUpdates", map[string]any{
+			"offset":          offset,
+			"timeout":         telegramPollTimeout,
+			"allowed_updates": []string{"message"},
+		}, &updates)
+		cancel()
+
+		if ctx.Err() != nil {
+			slog.Info("Telegram bot polling stopped")
+			return
+		}
+		if err != nil {
+			slog.Warn("Telegram: getUpdates failed", "error", err)
+			select {
+			case <-time.After(5 * time.Second):
+			case <-ctx.Done():
+				slog.Info("Telegram bot polling stopped")
+				return
+			}
+			continue
+		}
+
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			if u.Message == nil {
+				continue
+			}
+			reply := t.handleCommand(u.Message.Chat.ID, u.Message.Text)
+			if reply == "" {
+				continue
+			}
+			if err := t.sendMessage(ctx, u.Message.Chat.ID, reply); err != nil {
+				slog.Warn("Telegram: failed to reply", "chat_id", u.Message.Chat.ID, "error", err)
+			}
+		}
+	}
+}
+
+func (t *TelegramNotifier) handleCommand(chatID int64, text string) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return ""
+	}
+
+	// Commands in groups may be addressed as /command@botname
+	command := fields[0]
+	if idx := strings.Index(command, "@"); idx != -1 {
+		command = command[:idx]
+	}
+	args := fields[1:]
+
+	switch command {
+	case "/start", "/help":
+		return "可用命令:\n/subscribe &lt;主播ID&gt; - 订阅开播提醒\n/unsubscribe [主播ID] - 取消订阅 (不带参数则取消全部)\n/list - 查看已订阅的主播"
+
+	case "/subscribe":
+		if len(args) == 0 {
+			return "用法: /subscribe &lt;主播ID&gt;"
+		}
+		var lines []string
+		for _, id := range args {
+			streamer, ok := t.lookup(id)
+			if !ok {
+				lines = append(lines, fmt.Sprintf("未找到主播: %s", html.EscapeString(id)))
+				continue
+			}
+			if err := t.store.AddTelegramSubscription(chatID, id); err != nil {
+				slog.Error("Telegram: failed to add subscription", "chat_id", chatID, "streamer_id", id, "error", err)
+				lines = append(lines, fmt.Sprintf("订阅失败: %s", html.EscapeString(id)))
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("已订阅 <b>%s</b> (%s)", html.EscapeString(streamer.Name), html.EscapeString(id)))
+		}
+		return strings.Join(lines, "\n")
+
+	case "/unsubscribe":
+		if len(args) == 0 {
+			if err := t.store.RemoveAllTelegramSubscriptions(chatID); err != nil {
+				slog.Error("Telegram: failed to remove subscriptions", "chat_id", chatID, "error", err)
+				return "取消订阅失败"
+			}
+			return "已取消全部订阅"
+		}
+		var lines []string
+		for _, id := range args {
+			if err := t.store.RemoveTelegramSubscription(chatID, id); err != nil {
+				slog.Error("Telegram: failed to remove subscription", "chat_id", chatID, "streamer_id", id, "error", err)
+				lines = append(lines, fmt.Sprintf("取消订阅失败: %s", html.EscapeString(id)))
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("已取消订阅 %s", html.EscapeString(id)))
+		}
+		return strings.Join(lines, "\n")
+
+	case "/list":
+		ids, err := t.store.GetTelegramSubscriptions(chatID)
+		if err != nil {
+			slog.Error("Telegram: failed to list subscriptions", "chat_id", chatID, "error", err)
+			return "获取订阅列表失败"
+		}
+		if len(ids) == 0 {
+			return "暂无订阅"
+		}
+		lines := []string{"已订阅的主播:"}
+		for _, id := range ids {
+			streamer, ok := t.lookup(id)
+			if !ok {
+				lines = append(lines, fmt.Sprintf("• %s (已移除)", html.EscapeString(id)))
+				continue
+			}
+			status := "未开播"
+			if streamer.IsLive {
+				status = "🔴 直播中"
+			}
+			lines = append(lines, fmt.Sprintf("• <b>%s</b> (%s) %s - %s", html.EscapeString(streamer.Name), html.EscapeString(id), html.EscapeString(PlatformName(streamer.Platform)), status))
+		}
+		return strings.Join(lines, "\n")
+
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,202 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"cxtv-alerts/internal/model"
+)
+
+type telegramMessage struct {
+	ChatID    int64  `json:"chat_id"`
+	Text      string `json:"text"`
+	ParseMode string `json:"parse_mode"`
+}
+
+// newTelegramStub serves sendMessage, failing for the chats in failChats,
+// and returns the messages it received.
+func newTelegramStub(t *testing.T, failChats ...int64) (*httptest.Server, func() []telegramMessage) {
+	t.Helper()
+	var mu sync.Mutex
+	var sent []telegramMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bottoken/sendMessage" {
+			t.Errorf("path = %s", r.URL.Path)
+			http.Error(w, "unexpected path", http.StatusNotFound)
+			return
+		}
+		var msg telegramMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("decode body: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if slices.Contains(failChats, msg.ChatID) {
+			w.Write([]byte(`{"ok":false,"description":"Forbidden: bot was blocked by the user"}`))
+			return
+		}
+		mu.Lock()
+		sent = append(sent, msg)
+		mu.Unlock()
+		w.Write([]byte(`{"ok":true,"result":{}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []telegramMessage {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(sent)
+	}
+}
+
+// fakeTelegramStore keeps subscriptions in memory, by chat.
+type fakeTelegramStore struct {
+	subs map[int64][]string
+}
+
+func newFakeTelegramStore() *fakeTelegramStore {
+	return &fakeTelegramStore{subs: make(map[int64][]string)}
+}
+
+func (f *fakeTelegramStore) AddTelegramSubscription(chatID int64, streamerID string) error {
+	if !slices.Contains(f.subs[chatID], streamerID) {
+		f.subs[chatID] = append(f.subs[chatID], streamerID)
+	}
+	return nil
+}
+
+func (f *fakeTelegramStore) RemoveTelegramSubscription(chatID int64, streamerID string) error {
+	f.subs[chatID] = slices.DeleteFunc(f.subs[chatID], func(id string) bool { return id == streamerID })
+	return nil
+}
+
+func (f *fakeTelegramStore) RemoveAllTelegramSubscriptions(chatID int64) error {
+	delete(f.subs, chatID)
+	return nil
+}
+
+func (f *fakeTelegramStore) GetTelegramSubscriptions(chatID int64) ([]string, error) {
+	return f.subs[chatID], nil
+}
+
+func (f *fakeTelegramStore) GetTelegramSubscribers(streamerID string) ([]int64, error) {
+	var chats []int64
+	for chatID, ids := range f.subs {
+		if slices.Contains(ids, streamerID) {
+			chats = append(chats, chatID)
+		}
+	}
+	slices.Sort(chats)
+	return chats, nil
+}
+
+func testLookup(id string) (model.Streamer, bool) {
+	if ev := testEvent(); id == ev.Streamer.ID {
+		return ev.Streamer, true
+	}
+	return model.Streamer{}, false
+}
+
+func TestTelegramNotifyEscapesHTML(t *testing.T) {
+	srv, sent := newTelegramStub(t)
+	store := newFakeTelegramStore()
+	store.AddTelegramSubscription(1, "bilibili_1")
+
+	ev := testEvent()
+	ev.Streamer.Name = "<b>A&B</b>"
+	ev.Streamer.Title = `"quoted" <i>title</i>`
+	n := NewTelegramNotifier("token", srv.URL, store, testLookup)
+	if err := n.Notify(context.Background(), ev); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	msgs := sent()
+	if len(msgs) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(msgs))
+	}
+	msg := msgs[0]
+	if msg.ChatID != 1 || msg.ParseMode != "HTML" {
+		t.Errorf("chat_id %d, parse_mode %q", msg.ChatID, msg.ParseMode)
+	}
+	want := "🔴 <b>&lt;b&gt;A&amp;B&lt;/b&gt;</b> 开播了\n" +
+		"平台: B站\n" +
+		"标题: &#34;quoted&#34; &lt;i&gt;title&lt;/i&gt;\n" +
+		"https://live.bilibili.com/123"
+	if msg.Text != want {
+		t.Errorf("text = %q, want %q", msg.Text, want)
+	}
+}
+
+func TestTelegramNotifyPartialFailure(t *testing.T) {
+	tests := []struct {
+		name      string
+		chats     []int64
+		failChats []int64
+		wantSent  int
+		wantErr   bool
+	}{
+		{"all delivered", []int64{1, 2}, nil, 2, false},
+		{"some delivered", []int64{1, 2, 3}, []int64{2}, 2, false},
+		{"none delivered", []int64{1, 2}, []int64{1, 2}, 0, true},
+		{"no subscribers", nil, nil, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, sent := newTelegramStub(t, tt.failChats...)
+			store := newFakeTelegramStore()
+			for _, chatID := range tt.chats {
+				store.AddTelegramSubscription(chatID, "bilibili_1")
+			}
+
+			n := NewTelegramNotifier("token", srv.URL, store, testLookup)
+			err := n.Notify(context.Background(), testEvent())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Notify error = %v, want error %v", err, tt.wantErr)
+			}
+			if got := len(sent()); got != tt.wantSent {
+				t.Errorf("sent %d messages, want %d", got, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestTelegramCommands(t *testing.T) {
+	store := newFakeTelegramStore()
+	n := NewTelegramNotifier("token", "", store, testLookup)
+	const chat = 7
+
+	steps := []struct {
+		text string
+		want string
+		subs []string
+	}{
+		{"hello", "", nil},
+		{"/list", "暂无订阅", nil},
+		{"/subscribe", "用法: /subscribe &lt;主播ID&gt;", nil},
+		{"/subscribe@cxtv_bot bilibili_1 <nope>", "已订阅 <b>测试主播</b> (bilibili_1)\n未找到主播: &lt;nope&gt;", []string{"bilibili_1"}},
+		{"/list", "已订阅的主播:\n• <b>测试主播</b> (bilibili_1) B站 - 🔴 直播中", []string{"bilibili_1"}},
+		{"/unsubscribe bilibili_1", "已取消订阅 bilibili_1", []string{}},
+		{"/subscribe bilibili_1", "已订阅 <b>测试主播</b> (bilibili_1)", []string{"bilibili_1"}},
+		{"/unsubscribe", "已取消全部订阅", nil},
+	}
+	for i, step := range steps {
+		if got := n.handleCommand(chat, step.text); got != step.want {
+			t.Errorf("step %d %q: reply %q, want %q", i, step.text, got, step.want)
+		}
+		if got := store.subs[chat]; fmt.Sprint(got) != fmt.Sprint(step.subs) {
+			t.Errorf("step %d %q: subscriptions %v, want %v", i, step.text, got, step.subs)
+		}
+	}
+
+	// A subscribed streamer that was removed from the config is still listed
+	store.AddTelegramSubscription(chat, "douyu_9")
+	if got := n.handleCommand(chat, "/list"); !strings.Contains(got, "• douyu_9 (已移除)") {
+		t.Errorf("list with removed streamer = %q", got)
+	}
+}
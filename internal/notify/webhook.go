@@ -0,0 +1,233 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"cxtv-alerts/internal/model"
+)
+
+// NewWebhook builds the notifier for a configured webhook target, wrapped in
+// the target's streamer/platform/event filter. publicURL is the externally
+// reachable base URL of this site, used to turn local avatar paths into
+// absolute links.
+func NewWebhook(cfg model.WebhookConfig, publicURL string) (Notifier, error) {
+	name := cfg.Name
+	if name == "" {
+		name = cfg.Type
+	}
+	publicURL = strings.TrimRight(publicURL, "/")
+
+	switch cfg.Type {
+	case "bark", "serverchan":
+		// url is an optional server override, the key identifies the receiver
+		if cfg.Key == "" {
+			return nil, fmt.Errorf("webhook %q: key is required", name)
+		}
+	default:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("webhook %q: url is required", name)
+		}
+	}
+
+	var n Notifier
+	switch cfg.Type {
+	case "bark":
+		n = NewBarkNotifier(name, cfg.URL, cfg.Key)
+	case "serverchan":
+		n = NewServerChanNotifier(name, cfg.URL, cfg.Key)
+	case "wecom":
+		n = NewWeComNotifier(name, cfg.URL)
+	case "dingtalk":
+		n = NewDingTalkNotifier(name, cfg.URL, cfg.Secret)
+	case "feishu":
+		n = NewFeishuNotifier(name, cfg.URL, cfg.Secret)
+	case "discord":
+		n = &DiscordNotifier{name: name, url: cfg.URL, publicURL: publicURL}
+	case "json":
+		jn, err := NewJSONWebhookNotifier(name, cfg.URL, cfg.Template, publicURL)
+		if err != nil {
+			return nil, err
+		}
+		n = jn
+	default:
+		return nil, fmt.Errorf("webhook %q: unknown type %q", name, cfg.Type)
+	}
+
+	eventTypes, err := EventTypes(cfg.Events, EventLiveStart, EventLiveEnd)
+	if err != nil {
+		return nil, fmt.Errorf("webhook %q: %w", name, err)
+	}
+
+	return Filtered(n, Filter{
+		Streamers: cfg.Streamers,
+		Platforms: cfg.Platforms,
+		Events:    eventTypes,
+	}), nil
+}
+
+// avatarURL prefers the locally cached avatar when the site has a public URL,
+// since platform CDNs often refuse hotlinking.
+func avatarURL(s model.Streamer, publicURL string) string {
+	if s.AvatarLocal != "" && publicURL != "" {
+		return publicURL + s.AvatarLocal
+	}
+	return s.Avatar
+}
+
+var platformColors = map[model.Platform]int{
+	model.PlatformBilibili: 0xfb7299,
+	model.PlatformDouyu:    0xff6a00,
+	model.PlatformDouyin:   0xfe2c55,
+	model.PlatformKuaishou: 0xff4906,
+	model.PlatformCC163:    0xff0000,
+	model.PlatformWeibo:    0xff8200,
+}
+
+const offlineColor = 0x808080
+
+// DiscordNotifier posts an embed to a Discord channel webhook.
+type DiscordNotifier struct {
+	name      string
+	url       string
+	publicURL string
+}
+
+func (d *DiscordNotifier) Name() string {
+	return d.name
+}
+
+type discordEmbed struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description,omitempty"`
+	URL         string              `json:"url,omitempty"`
+	Color       int                 `json:"color"`
+	Timestamp   string              `json:"timestamp"`
+	Thumbnail   *discordImage       `json:"thumbnail,omitempty"`
+	Fields      []discordEmbedField `json:"fields,omitempty"`
+}
+
+type discordImage struct {
+	URL string `json:"url"`
+}
+
+type discordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+func (d *DiscordNotifier) Notify(ctx context.Context, ev Event) error {
+	s := ev.Streamer
+	embed := discordEmbed{
+		URL:       s.RoomURL,
+		Timestamp: ev.Time.UTC().Format(time.RFC3339),
+		Fields: []discordEmbedField{
+			{Name: "平台", Value: PlatformName(s.Platform), Inline: true},
+		},
+	}
+
+	switch ev.Type {
+	case EventLiveStart:
+		embed.Title = fmt.Sprintf("🔴 %s 开播了", s.Name)
+		embed.Description = s.Title
+		embed.Color = platformColors[s.Platform]
+		if s.ViewerCount > 0 {
+			embed.Fields = append(embed.Fields, discordEmbedField{
+				Name: "观看人数", Value: fmt.Sprintf("%d", s.ViewerCount), Inline: true,
+			})
+		}
+	case EventLiveEnd:
+		embed.Title = fmt.Sprintf("%s 下播了", s.Name)
+		embed.Color = offlineColor
+	case EventTitleChange:
+		embed.Title = fmt.Sprintf("%s 更换了标题", s.Name)
+		embed.Description = s.Title
+		embed.Color = platformColors[s.Platform]
+		if ev.PrevTitle != "" {
+			embed.Fields = append(embed.Fields, discordEmbedField{
+				Name: "原标题", Value: ev.PrevTitle, Inline: false,
+			})
+		}
+	default:
+		return nil
+	}
+
+	if avatar := avatarURL(s, d.publicURL); avatar != "" {
+		embed.Thumbnail = &discordImage{URL: avatar}
+	}
+
+	_, err := postJSON(ctx, d.url, map[string]any{
+		"embeds": []discordEmbed{embed},
+	})
+	return err
+}
+
+// JSONWebhookNotifier posts a JSON body rendered from a user-defined
+// text/template. Without a template the event itself is posted.
+type JSONWebhookNotifier struct {
+	name      string
+	url       string
+	tmpl      *template.Template
+	publicURL string
+}
+
+// WebhookData is the value templates are executed with.
+type WebhookData struct {
+	Event
+	AvatarURL    string `json:"avatar_url,omitempty"`
+	PlatformName string `json:"platform_name"`
+}
+
+var templateFuncs = template.FuncMap{
+	// json encodes a value as a JSON literal, so strings are quoted and
+	// escaped: {"title": {{ json .Streamer.Title }}}
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+func NewJSONWebhookNotifier(name, url, body, publicURL string) (*JSONWebhookNotifier, error) {
+	n := &JSONWebhookNotifier{name: name, url: url, publicURL: publicURL}
+	if body != "" {
+		tmpl, err := template.New(name).Funcs(templateFuncs).Parse(body)
+		if err != nil {
+			return nil, fmt.Errorf("webhook %q: invalid template: %w", name, err)
+		}
+		n.tmpl = tmpl
+	}
+	return n, nil
+}
+
+func (j *JSONWebhookNotifier) Name() string {
+	return j.name
+}
+
+func (j *JSONWebhookNotifier) Notify(ctx context.Context, ev Event) error {
+	data := WebhookData{
+		Event:        ev,
+		AvatarURL:    avatarURL(ev.Streamer, j.publicURL),
+		PlatformName: PlatformName(ev.Streamer.Platform),
+	}
+
+	if j.tmpl == nil {
+		_, err := postJSON(ctx, j.url, data)
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := j.tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("render template: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return fmt.Errorf("template did not produce valid JSON")
+	}
+	_, err := post(ctx, j.url, "application/json", buf.Bytes())
+	return err
+}
@@ -0,0 +1,130 @@
+package notify
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDiscordNotifier(t *testing.T) {
+	tests := []struct {
+		name  string
+		event func() Event
+		want  string
+	}{
+		{
+			name: "live start",
+			event: func() Event {
+				ev := testEvent()
+				ev.Streamer.ViewerCount = 1234
+				return ev
+			},
+			want: `{"embeds":[{"title":"🔴 测试主播 开播了","description":"今天播点什么","url":"https://live.bilibili.com/123",` +
+				`"color":16478873,"timestamp":"2026-01-02T03:04:05Z","thumbnail":{"url":"https://example.com/avatar.jpg"},` +
+				`"fields":[{"name":"平台","value":"B站","inline":true},{"name":"观看人数","value":"1234","inline":true}]}]}`,
+		},
+		{
+			name: "live end with local avatar",
+			event: func() Event {
+				ev := testEvent()
+				ev.Type = EventLiveEnd
+				ev.Streamer.AvatarLocal = "/static/avatars/bilibili_1.jpg"
+				return ev
+			},
+			want: `{"embeds":[{"title":"测试主播 下播了","url":"https://live.bilibili.com/123",` +
+				`"color":8421504,"timestamp":"2026-01-02T03:04:05Z","thumbnail":{"url":"https://tv.example.com/static/avatars/bilibili_1.jpg"},` +
+				`"fields":[{"name":"平台","value":"B站","inline":true}]}]}`,
+		},
+		{
+			name: "title change",
+			event: func() Event {
+				ev := testEvent()
+				ev.Type = EventTitleChange
+				ev.Streamer.Title = "say \"hi\"\nsecond line"
+				ev.PrevTitle = "旧标题"
+				return ev
+			},
+			want: `{"embeds":[{"title":"测试主播 更换了标题","description":"say \"hi\"\nsecond line","url":"https://live.bilibili.com/123",` +
+				`"color":16478873,"timestamp":"2026-01-02T03:04:05Z","thumbnail":{"url":"https://example.com/avatar.jpg"},` +
+				`"fields":[{"name":"平台","value":"B站","inline":true},{"name":"原标题","value":"旧标题","inline":false}]}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, next := newStub(t, "")
+			n := &DiscordNotifier{name: "discord", url: url, publicURL: "https://tv.example.com"}
+			if err := n.Notify(context.Background(), tt.event()); err != nil {
+				t.Fatalf("Notify: %v", err)
+			}
+			if got := string(next().Body); got != tt.want {
+				t.Errorf("body\n got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONWebhookNotifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		title    string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "escaped fields",
+			template: `{"text": {{ json .Streamer.Title }}, "platform": {{ json .PlatformName }}, "type": "{{ .Type }}"}`,
+			title:    "say \"hi\"\nsecond line",
+			want:     `{"text": "say \"hi\"\nsecond line", "platform": "B站", "type": "live_start"}`,
+		},
+		{
+			name:     "nested values",
+			template: `{"streamer": {{ json .Streamer.Name }}, "session": {{ .SessionID }}, "avatar": {{ json .AvatarURL }}}`,
+			title:    "今天播点什么",
+			want:     `{"streamer": "测试主播", "session": 42, "avatar": "https://example.com/avatar.jpg"}`,
+		},
+		{
+			name:     "unescaped title",
+			template: `{"text": "{{ .Streamer.Title }}"}`,
+			title:    "say \"hi\"",
+			wantErr:  true,
+		},
+		{
+			name:  "no template",
+			title: "say \"hi\"\nsecond line",
+			want: `{"type":"live_start","streamer":{"id":"bilibili_1","name":"测试主播","platform":"bilibili","room_id":"123",` +
+				`"avatar":"https://example.com/avatar.jpg","is_live":true,"title":"say \"hi\"\nsecond line",` +
+				`"room_url":"https://live.bilibili.com/123"},"session_id":42,"time":"2026-01-02T03:04:05Z",` +
+				`"avatar_url":"https://example.com/avatar.jpg","platform_name":"B站"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, next := newStub(t, "")
+			n, err := NewJSONWebhookNotifier("json", url, tt.template, "")
+			if err != nil {
+				t.Fatalf("NewJSONWebhookNotifier: %v", err)
+			}
+			ev := testEvent()
+			ev.Streamer.Title = tt.title
+			err = n.Notify(context.Background(), ev)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error for invalid JSON")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Notify: %v", err)
+			}
+			if got := string(next().Body); got != tt.want {
+				t.Errorf("body\n got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONWebhookInvalidTemplate(t *testing.T) {
+	if _, err := NewJSONWebhookNotifier("json", "http://example.com", `{"text": {{ .Streamer.Title }`, ""); err == nil {
+		t.Fatal("expected error for unparsable template")
+	}
+}
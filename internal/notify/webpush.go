@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"cxtv-alerts/internal/model"
+	"cxtv-alerts/internal/webpush"
+)
+
+// PushStore persists browser push subscriptions.
+type PushStore interface {
+	GetPushSubscriptions(streamerID string) ([]model.PushSubscription, error)
+	DeletePushSubscription(endpoint string) error
+}
+
+// WebPushNotifier delivers live start events to subscribed browsers.
+type WebPushNotifier struct {
+	vapid *webpush.VAPID
+	store PushStore
+}
+
+func NewWebPushNotifier(vapid *webpush.VAPID, store PushStore) *WebPushNotifier {
+	return &WebPushNotifier{vapid: vapid, store: store}
+}
+
+func (w *WebPushNotifier) Name() string {
+	return "webpush"
+}
+
+// pushPayload is read by web/sw.js.
+type pushPayload struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	Icon  string `json:"icon,omitempty"`
+	URL   string `json:"url,omitempty"`
+	Tag   string `json:"tag"`
+}
+
+func (w *WebPushNotifier) Notify(ctx context.Context, ev Event) error {
+	if ev.Type != EventLiveStart {
+		return nil
+	}
+
+	subs, err := w.store.GetPushSubscriptions(ev.Streamer.ID)
+	if err != nil {
+		return err
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	title, body := formatMessage(ev)
+	icon := ev.Streamer.AvatarLocal
+	if icon == "" {
+		icon = ev.Streamer.Avatar
+	}
+	payload, err := json.Marshal(pushPayload{
+		Title: title,
+		Body:  body,
+		Icon:  icon,
+		URL:   ev.Streamer.RoomURL,
+		Tag:   ev.Streamer.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// As with Telegram, only fail (and retry) when nothing was delivered
+	var lastErr error
+	sent := 0
+	for _, sub := range subs {
+		err := w.vapid.Send(ctx, sub, payload, time.Hour)
+		if errors.Is(err, webpush.ErrGone) {
+			slog.Info("Web push: pruning expired subscription", "endpoint", sub.Endpoint)
+			if err := w.store.DeletePushSubscription(sub.Endpoint); err != nil {
+				slog.Error("Web push: failed to delete subscription", "endpoint", sub.Endpoint, "error", err)
+			}
+			continue
+		}
+		if err != nil {
+			slog.Warn("Web push: failed to notify", "endpoint", sub.Endpoint, "streamer_id", ev.Streamer.ID, "error", err)
+			lastErr = err
+			continue
+		}
+		sent++
+	}
+	if sent == 0 {
+		return lastErr
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// WeComNotifier posts Markdown messages to a WeCom (企业微信) group robot.
+type WeComNotifier struct {
+	name string
+	url  string
+}
+
+func NewWeComNotifier(name, webhookURL string) *WeComNotifier {
+	return &WeComNotifier{name: name, url: webhookURL}
+}
+
+func (w *WeComNotifier) Name() string {
+	return w.name
+}
+
+func (w *WeComNotifier) Notify(ctx context.Context, ev Event) error {
+	title, body := formatMessage(ev)
+	content := fmt.Sprintf("**%s**\n%s", title, body)
+	if ev.Streamer.RoomURL != "" {
+		content += fmt.Sprintf("\n[打开直播间](%s)", ev.Streamer.RoomURL)
+	}
+
+	respBody, err := postJSON(ctx, w.url, map[string]any{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"content": content,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return checkErrcode("wecom", respBody)
+}
+
+// checkErrcode checks the {"errcode": 0, "errmsg": "ok"} response shared by
+// the WeCom and DingTalk robots.
+func checkErrcode(channel string, respBody []byte) error {
+	var result struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return err
+	}
+	if result.ErrCode != 0 {
+		return fmt.Errorf("%s error %d: %s", channel, result.ErrCode, result.ErrMsg)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestWeComNotifier(t *testing.T) {
+	url, next := newStub(t, `{"errcode":0,"errmsg":"ok"}`)
+
+	n := NewWeComNotifier("wecom", url+"/cgi-bin/webhook/send?key=abc")
+	if err := n.Notify(context.Background(), testEvent()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	req := next()
+	if req.URL.RawQuery != "key=abc" {
+		t.Errorf("query = %q, want key=abc", req.URL.RawQuery)
+	}
+	var got struct {
+		MsgType  string `json:"msgtype"`
+		Markdown struct {
+			Content string `json:"content"`
+		} `json:"markdown"`
+	}
+	req.decodeJSON(t, &got)
+	if got.MsgType != "markdown" {
+		t.Errorf("msgtype = %q", got.MsgType)
+	}
+	if !strings.HasPrefix(got.Markdown.Content, "**测试主播 开播了**") {
+		t.Errorf("content = %q", got.Markdown.Content)
+	}
+}
+
+func TestWeComNotifierError(t *testing.T) {
+	url, _ := newStub(t, `{"errcode":93000,"errmsg":"invalid webhook url"}`)
+
+	n := NewWeComNotifier("wecom", url)
+	if err := n.Notify(context.Background(), testEvent()); err == nil {
+		t.Fatal("expected error for non-zero errcode")
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"cxtv-alerts/internal/model"
+)
+
+// adminTokenEnv overrides the admin_token setting, so the token can be kept
+// out of the settings file.
+const adminTokenEnv = "CXTV_ADMIN_TOKEN"
+
+var (
+	ErrInvalidStreamer   = errors.New("invalid streamer")
+	ErrDuplicateStreamer = errors.New("streamer already exists")
+)
+
+// AdminToken returns the bearer token required by the admin API, or "" if
+// the admin API is disabled.
+func (s *Service) AdminToken() string {
+	if token := os.Getenv(adminTokenEnv); token != "" {
+		return token
+	}
+	_, settings := s.current()
+	return settings.AdminToken
+}
+
+// GetStreamerConfigs returns the configured streamers in file order.
+func (s *Service) GetStreamerConfigs() []model.StreamerConfig {
+	config, _ := s.current()
+	return slices.Clone(config.Streamers)
+}
+
+// AddStreamer appends a streamer to streamers.json and starts tracking it.
+func (s *Service) AddStreamer(sc model.StreamerConfig) error {
+	return s.updateConfig(func(streamers []model.StreamerConfig) ([]model.StreamerConfig, error) {
+		if indexOfStreamer(streamers, sc.ID) != -1 {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateStreamer, sc.ID)
+		}
+		return append(streamers, sc), nil
+	})
+}
+
+// UpdateStreamer replaces the config of an existing streamer in place.
+func (s *Service) UpdateStreamer(id string, sc model.StreamerConfig) error {
+	if sc.ID != id {
+		return fmt.Errorf("%w: id cannot be changed", ErrInvalidStreamer)
+	}
+	return s.updateConfig(func(streamers []model.StreamerConfig) ([]model.StreamerConfig, error) {
+		i := indexOfStreamer(streamers, id)
+		if i == -1 {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownStreamer, id)
+		}
+		streamers[i] = sc
+		return streamers, nil
+	})
+}
+
+// RemoveStreamer deletes a streamer from streamers.json, closing its open
+// session.
+func (s *Service) RemoveStreamer(id string) error {
+	return s.updateConfig(func(streamers []model.StreamerConfig) ([]model.StreamerConfig, error) {
+		i := indexOfStreamer(streamers, id)
+		if i == -1 {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownStreamer, id)
+		}
+		return slices.Delete(streamers, i, i+1), nil
+	})
+}
+
+func indexOfStreamer(streamers []model.StreamerConfig, id string) int {
+	return slices.IndexFunc(streamers, func(sc model.StreamerConfig) bool { return sc.ID == id })
+}
+
+// updateConfig applies edit to a copy of the streamer list, validates the
+// result, writes it to streamers.json and applies it. The file is the
+// source of truth, so a hand edit that is not yet reloaded is picked up
+// first rather than overwritten.
+func (s *Service) updateConfig(edit func([]model.StreamerConfig) ([]model.StreamerConfig, error)) error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	_, settings := s.current()
+	config, err := loadConfig(s.configPath)
+	if err != nil {
+		return fmt.Errorf("%s: %w", s.configPath, err)
+	}
+
+	streamers, err := edit(slices.Clone(config.Streamers))
+	if err != nil {
+		return err
+	}
+	config = &model.Config{Streamers: streamers}
+	if err := Validate(config, settings, "", "").Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidStreamer, err)
+	}
+
+	if err := writeConfig(s.configPath, config); err != nil {
+		return err
+	}
+	s.configMod = statFile(s.configPath)
+
+	s.apply(config, settings)
+	return nil
+}
+
+// writeConfig replaces the config file atomically, so a crash mid-write
+// never leaves a truncated file behind.
+func writeConfig(path string, config *model.Config) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false) // keep & in avatar URLs readable
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(config); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil {
+		os.Chmod(tmp.Name(), info.Mode().Perm())
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -1,10 +1,11 @@
 package service
 
 import (
+	"context"
 	"crypto/md5"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -12,25 +13,45 @@ import (
 	"time"
 )
 
-const avatarDir = "web/avatars"
-
-func (s *Service) StartAvatarUpdater() {
-	// Update avatars immediately on startup
-	go s.updateAllAvatars()
-
-	// Then update daily
-	ticker := time.NewTicker(24 * time.Hour)
+// StartAvatarUpdater downloads avatars on startup and then daily until ctx
+// is cancelled.
+func (s *Service) StartAvatarUpdater(ctx context.Context) {
+	s.loops.Add(1)
 	go func() {
-		for range ticker.C {
-			s.updateAllAvatars()
+		defer s.loops.Done()
+
+		// Update avatars immediately on startup
+		s.updateAllAvatars(ctx)
+
+		// Then update daily
+		ticker := time.NewTicker(24 * time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.updateAllAvatars(ctx)
+			case <-ctx.Done():
+				return
+			}
 		}
 	}()
 }
 
-func (s *Service) updateAllAvatars() {
-	log.Println("Starting avatar update...")
+func (s *Service) updateAllAvatars(ctx context.Context) {
+	slog.Info("Starting avatar update")
+
+	if err := os.MkdirAll(s.avatarDir, 0755); err != nil {
+		slog.Error("Error creating avatar directory", "dir", s.avatarDir, "error", err)
+		return
+	}
+
+	config, _ := s.current()
+	for _, sc := range config.Streamers {
+		if ctx.Err() != nil {
+			slog.Info("Avatar update aborted")
+			return
+		}
 
-	for _, sc := range s.config.Streamers {
 		avatarURL := sc.Avatar
 		if avatarURL == "" {
 			continue
@@ -39,14 +60,14 @@ func (s *Service) updateAllAvatars() {
 		// Check if we need to update
 		_, currentLocal, lastUpdated, err := s.db.GetAvatarInfo(sc.ID)
 		if err != nil {
-			log.Printf("Error getting avatar info for %s: %v", sc.Name, err)
+			slog.Error("Error getting avatar info", "streamer_id", sc.ID, "error", err)
 			continue
 		}
 
 		// Skip if updated within 24 hours and local file exists
 		if lastUpdated != nil && time.Since(*lastUpdated) < 24*time.Hour {
 			if currentLocal != "" {
-				localPath := filepath.Join(avatarDir, currentLocal)
+				localPath := filepath.Join(s.avatarDir, currentLocal)
 				if _, err := os.Stat(localPath); err == nil {
 					continue
 				}
@@ -54,15 +75,17 @@ func (s *Service) updateAllAvatars() {
 		}
 
 		// Download avatar
-		localFile, err := s.downloadAvatar(sc.ID, avatarURL)
+		localFile, err := s.downloadAvatar(ctx, sc.ID, avatarURL)
 		if err != nil {
-			log.Printf("Error downloading avatar for %s: %v", sc.Name, err)
+			if ctx.Err() == nil {
+				slog.Warn("Error downloading avatar", "streamer_id", sc.ID, "url", avatarURL, "error", err)
+			}
 			continue
 		}
 
 		// Update database
 		if err := s.db.UpdateAvatar(sc.ID, avatarURL, localFile); err != nil {
-			log.Printf("Error updating avatar record for %s: %v", sc.Name, err)
+			slog.Error("Error updating avatar record", "streamer_id", sc.ID, "error", err)
 			continue
 		}
 
@@ -77,22 +100,22 @@ func (s *Service) updateAllAvatars() {
 		time.Sleep(100 * time.Millisecond)
 	}
 
-	log.Println("Avatar update complete")
+	slog.Info("Avatar update complete")
 }
 
-func (s *Service) downloadAvatar(streamerID, url string) (string, error) {
+func (s *Service) downloadAvatar(ctx context.Context, streamerID, url string) (string, error) {
 	// Create a unique filename based on streamer ID and URL hash
 	hash := fmt.Sprintf("%x", md5.Sum([]byte(url)))[:8]
 	ext := getExtension(url)
 	filename := fmt.Sprintf("%s_%s%s", streamerID, hash, ext)
-	localPath := filepath.Join(avatarDir, filename)
+	localPath := filepath.Join(s.avatarDir, filename)
 
 	// Create HTTP client with timeout
 	client := &http.Client{
 		Timeout: 30 * time.Second,
 	}
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return "", err
 	}
@@ -153,7 +176,7 @@ func (s *Service) loadLocalAvatars() {
 			continue
 		}
 		if avatarLocal != "" {
-			localPath := filepath.Join(avatarDir, avatarLocal)
+			localPath := filepath.Join(s.avatarDir, avatarLocal)
 			if _, err := os.Stat(localPath); err == nil {
 				streamer.AvatarLocal = "/static/avatars/" + avatarLocal
 			}
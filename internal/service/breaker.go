@@ -0,0 +1,192 @@
+package service
+
+import (
+	"log/slog"
+	"math/rand"
+	"sort"
+	"time"
+
+	"cxtv-alerts/internal/model"
+)
+
+// Circuit breaker states of a platform
+const (
+	BreakerClosed   = "closed"    // querying normally
+	BreakerOpen     = "open"      // paused until the backoff expires
+	BreakerHalfOpen = "half_open" // a single probe query decides whether to resume
+)
+
+// breaker pauses a platform that is blocking or rate limiting us, so we stop
+// making it worse by querying every room on every scan.
+type breaker struct {
+	state     string
+	failures  int // consecutive blocked or rate limited queries
+	trips     int // consecutive pauses, each doubling the backoff
+	lastError string
+	pausedAt  time.Time // when the platform was first paused since it last worked
+	openUntil time.Time
+	since     time.Time // when state last changed
+}
+
+// PlatformStatus is a platform's circuit breaker state, as shown by the API.
+type PlatformStatus struct {
+	Platform  model.Platform `json:"platform"`
+	Streamers int            `json:"streamers"`
+	State     string         `json:"state"`
+	Failures  int            `json:"failures"`             // consecutive blocked or rate limited queries
+	Trips     int            `json:"trips,omitempty"`      // consecutive pauses
+	LastError string         `json:"last_error,omitempty"` // kind of the error that opened the breaker
+	Since     *time.Time     `json:"since,omitempty"`
+	OpenUntil *time.Time     `json:"open_until,omitempty"`
+}
+
+// tripsBreaker reports whether an error kind means the platform is pushing
+// back. Other failures are per room or transient and never pause a platform.
+func tripsBreaker(kind string) bool {
+	return kind == "blocked" || kind == "rate_limited"
+}
+
+// breakerBackoff is the pause after the trips-th consecutive trip: the
+// base backoff doubled for every earlier trip, capped, with ±20% jitter so
+// a restart does not line up all instances' probes.
+func breakerBackoff(settings *model.Settings, trips int) time.Duration {
+	backoff := time.Duration(settings.BreakerBackoffMinutes) * time.Minute
+	limit := time.Duration(settings.BreakerMaxBackoffMinutes) * time.Minute
+	for i := 1; i < trips && backoff < limit; i++ {
+		backoff *= 2
+	}
+	if backoff > limit {
+		backoff = limit
+	}
+	return time.Duration(float64(backoff) * (0.8 + 0.4*rand.Float64()))
+}
+
+// breakerFor returns the platform's breaker, creating a closed one. Must
+// hold s.mu.
+func (s *Service) breakerFor(platform model.Platform) *breaker {
+	b := s.breakers[platform]
+	if b == nil {
+		b = &breaker{state: BreakerClosed}
+		s.breakers[platform] = b
+	}
+	return b
+}
+
+// allowQuery reports whether a platform may be queried. An open breaker
+// whose backoff has expired turns half-open and lets exactly one query
+// through as the probe.
+func (s *Service) allowQuery(logger *slog.Logger, platform model.Platform) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.breakerFor(platform)
+	switch b.state {
+	case BreakerOpen:
+		if time.Now().Before(b.openUntil) {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.since = time.Now()
+		logger.Info("Probing paused platform", "platform", platform)
+		return true
+	case BreakerHalfOpen:
+		// The probe has not reported back
+		return false
+	default:
+		return true
+	}
+}
+
+// pausedUntil returns when a paused platform may be probed, or the zero
+// time. Must hold s.mu.
+func (s *Service) pausedUntil(platform model.Platform) time.Time {
+	if b := s.breakers[platform]; b != nil && b.state == BreakerOpen {
+		return b.openUntil
+	}
+	return time.Time{}
+}
+
+// recordQuery updates a platform's breaker with the outcome of a query,
+// errKind being empty for a success. Must hold s.mu.
+func (s *Service) recordQuery(logger *slog.Logger, platform model.Platform, errKind string) {
+	b := s.breakerFor(platform)
+	if !tripsBreaker(errKind) {
+		if errKind == "" {
+			b.failures = 0
+		}
+		if b.state == BreakerHalfOpen {
+			// The probe got past the block, whatever else went wrong
+			logger.Info("Platform resumed", "platform", platform, "paused", time.Since(b.pausedAt).Round(time.Second))
+			b.state = BreakerClosed
+			b.since = time.Now()
+			b.trips = 0
+			b.failures = 0
+		}
+		return
+	}
+
+	b.failures++
+	b.lastError = errKind
+	if b.state == BreakerClosed && b.failures < s.settings.BreakerThreshold {
+		return
+	}
+	if b.state == BreakerClosed {
+		b.pausedAt = time.Now()
+	}
+	b.trips++
+	backoff := breakerBackoff(s.settings, b.trips)
+	b.state = BreakerOpen
+	b.since = time.Now()
+	b.openUntil = b.since.Add(backoff)
+	logger.Warn("Platform paused",
+		"platform", platform,
+		"kind", errKind,
+		"failures", b.failures,
+		"trips", b.trips,
+		"backoff", backoff.Round(time.Second))
+}
+
+// cancelProbe reopens a half-open breaker whose probe was cancelled, so
+// the next scan probes again instead of waiting forever.
+func (s *Service) cancelProbe(platform model.Platform) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b := s.breakers[platform]; b != nil && b.state == BreakerHalfOpen {
+		b.state = BreakerOpen
+	}
+}
+
+// GetPlatforms returns the breaker state of every platform with streamers.
+func (s *Service) GetPlatforms() []PlatformStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[model.Platform]int)
+	for _, streamer := range s.streamers {
+		counts[streamer.Platform]++
+	}
+
+	platforms := make([]PlatformStatus, 0, len(counts))
+	for platform, n := range counts {
+		st := PlatformStatus{Platform: platform, Streamers: n, State: BreakerClosed}
+		if b := s.breakers[platform]; b != nil {
+			st.State = b.state
+			st.Failures = b.failures
+			st.Trips = b.trips
+			if b.state != BreakerClosed {
+				st.LastError = b.lastError
+			}
+			if !b.since.IsZero() {
+				since := b.since
+				st.Since = &since
+			}
+			if b.state == BreakerOpen {
+				openUntil := b.openUntil
+				st.OpenUntil = &openUntil
+			}
+		}
+		platforms = append(platforms, st)
+	}
+	sort.Slice(platforms, func(i, j int) bool { return platforms[i].Platform < platforms[j].Platform })
+	return platforms
+}
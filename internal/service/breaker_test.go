@@ -0,0 +1,82 @@
+package service
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"cxtv-alerts/internal/model"
+)
+
+func TestBreakerBackoff(t *testing.T) {
+	settings := &model.Settings{BreakerBackoffMinutes: 10, BreakerMaxBackoffMinutes: 60}
+	tests := []struct {
+		trips int
+		want  time.Duration
+	}{
+		{1, 10 * time.Minute},
+		{2, 20 * time.Minute},
+		{3, 40 * time.Minute},
+		{4, 60 * time.Minute},
+		{20, 60 * time.Minute},
+	}
+	for _, tt := range tests {
+		got := breakerBackoff(settings, tt.trips)
+		if got < tt.want*8/10 || got > tt.want*12/10 {
+			t.Errorf("trip %d: backoff %s, want %s ±20%%", tt.trips, got, tt.want)
+		}
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	s := &Service{
+		settings: &model.Settings{BreakerThreshold: 2, BreakerBackoffMinutes: 10, BreakerMaxBackoffMinutes: 60},
+		breakers: make(map[model.Platform]*breaker),
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	p := model.PlatformDouyin
+	state := func() string { return s.breakerFor(p).state }
+
+	// Failures that are not the platform pushing back never trip it
+	for i := 0; i < 5; i++ {
+		s.recordQuery(logger, p, "transport")
+	}
+	s.recordQuery(logger, p, "blocked")
+	if state() != BreakerClosed || !s.allowQuery(logger, p) {
+		t.Fatalf("state %s after one block, want closed", state())
+	}
+	s.recordQuery(logger, p, "rate_limited")
+	if state() != BreakerOpen || s.allowQuery(logger, p) {
+		t.Fatalf("state %s after two blocks, want open", state())
+	}
+
+	// An expired backoff lets exactly one probe through
+	s.breakers[p].openUntil = time.Now()
+	if !s.allowQuery(logger, p) || state() != BreakerHalfOpen {
+		t.Fatalf("state %s after backoff, want half_open", state())
+	}
+	if s.allowQuery(logger, p) {
+		t.Fatal("second query allowed while probing")
+	}
+
+	// A failed probe pauses again, for longer
+	first := s.breakers[p].openUntil
+	s.recordQuery(logger, p, "blocked")
+	if state() != BreakerOpen || s.breakers[p].trips != 2 || !s.breakers[p].openUntil.After(first.Add(10*time.Minute)) {
+		t.Fatalf("after failed probe: state %s, trips %d", state(), s.breakers[p].trips)
+	}
+
+	// A cancelled probe is retried
+	s.breakers[p].openUntil = time.Now()
+	s.allowQuery(logger, p)
+	s.cancelProbe(p)
+	if !s.allowQuery(logger, p) {
+		t.Fatal("probe not retried after cancellation")
+	}
+
+	s.recordQuery(logger, p, "")
+	if state() != BreakerClosed || s.breakers[p].trips != 0 || s.breakers[p].failures != 0 {
+		t.Fatalf("after successful probe: %+v", *s.breakers[p])
+	}
+}
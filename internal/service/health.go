@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"cxtv-alerts/internal/model"
+)
+
+// staleScanFactor is how many scan intervals a platform's worker may go
+// without finishing a query, while streamers are due, before the service
+// reports itself not ready.
+const staleScanFactor = 3
+
+// PlatformHealth summarises the recent query results of one platform.
+type PlatformHealth struct {
+	Platform      model.Platform `json:"platform"`
+	Streamers     int            `json:"streamers"`
+	Failing       int            `json:"failing"`        // streamers whose last query failed
+	FailureStreak int            `json:"failure_streak"` // consecutive failed queries across the platform
+	LastSuccess   *time.Time     `json:"last_success,omitempty"`
+}
+
+// Readiness reports whether the scanner is keeping the status up to date.
+type Readiness struct {
+	Ready     bool             `json:"ready"`
+	Reason    string           `json:"reason,omitempty"`
+	LastQuery *time.Time       `json:"last_query,omitempty"`
+	Platforms []PlatformHealth `json:"platforms"`
+}
+
+// Ping checks that the database is reachable.
+func (s *Service) Ping(ctx context.Context) error {
+	return s.db.Ping(ctx)
+}
+
+// Readiness is not ready until every streamer due at startup has been
+// queried, and again when a platform's worker has not finished a query for
+// several intervals although streamers are due, e.g. because it is stuck.
+// Paused platforms are not behind.
+func (s *Service) Readiness() Readiness {
+	_, settings := s.current()
+	maxAge := staleScanFactor * time.Duration(settings.ScanIntervalMinutes) * time.Minute
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r := Readiness{Ready: true, Platforms: s.platformHealth()}
+	now := time.Now()
+	for _, h := range r.Platforms {
+		if q := s.queues[h.Platform]; q != nil {
+			if reason := q.behind(now, s.pausedUntil(h.Platform), maxAge); reason != "" {
+				r.Ready = false
+				r.Reason = fmt.Sprintf("%s: %s", h.Platform, reason)
+				break
+			}
+		}
+	}
+	if !s.lastQuery.IsZero() {
+		lastQuery := s.lastQuery
+		r.LastQuery = &lastQuery
+	}
+	return r
+}
+
+// platformHealth must hold s.mu.
+func (s *Service) platformHealth() []PlatformHealth {
+	byPlatform := make(map[model.Platform]*PlatformHealth)
+	for id, streamer := range s.streamers {
+		h := byPlatform[streamer.Platform]
+		if h == nil {
+			h = &PlatformHealth{
+				Platform:      streamer.Platform,
+				FailureStreak: s.platformFailures[streamer.Platform],
+			}
+			byPlatform[streamer.Platform] = h
+		}
+		h.Streamers++
+		if streamer.LastError != "" {
+			h.Failing++
+		}
+		if t, ok := s.lastSuccess[id]; ok && (h.LastSuccess == nil || t.After(*h.LastSuccess)) {
+			h.LastSuccess = &t
+		}
+	}
+
+	health := make([]PlatformHealth, 0, len(byPlatform))
+	for _, h := range byPlatform {
+		health = append(health, *h)
+	}
+	sort.Slice(health, func(i, j int) bool { return health[i].Platform < health[j].Platform })
+	return health
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"time"
+
+	"cxtv-alerts/internal/metrics"
+)
+
+// scanMetrics are the scanner's Prometheus metrics. Values derived from
+// streamer state are collected on scrape instead of being kept up to date.
+type scanMetrics struct {
+	queries   *metrics.Counter
+	successes *metrics.Counter
+	failures  *metrics.Counter
+	latency   *metrics.Histogram
+	cycles    *metrics.Histogram
+	lag       *metrics.Histogram
+}
+
+func (s *Service) initMetrics() {
+	r := s.registry
+	s.metrics = scanMetrics{
+		queries: r.Counter("cxtv_scan_queries_total",
+			"Status queries sent to a platform.", "platform"),
+		successes: r.Counter("cxtv_scan_successes_total",
+			"Status queries that returned a result.", "platform"),
+		failures: r.Counter("cxtv_scan_failures_total",
+			"Status queries that failed, by error class.", "platform", "class"),
+		latency: r.Histogram("cxtv_scan_request_duration_seconds",
+			"Time taken by status queries, including failed ones.", metrics.DefaultBuckets, "platform"),
+		cycles: r.Histogram("cxtv_scan_cycle_duration_seconds",
+			"Time taken by a platform to query every streamer that was due when it started.", []float64{10, 30, 60, 120, 300, 600, 1200, 1800}, "platform"),
+		lag: r.Histogram("cxtv_scan_lag_seconds",
+			"Time from a streamer's query falling due to it being sent.", []float64{1, 5, 10, 30, 60, 120, 300, 600, 1800}, "platform"),
+	}
+
+	r.GaugeFunc("cxtv_live_streamers", "Streamers currently live.", []string{"platform"},
+		func(set func(float64, ...string)) {
+			s.mu.RLock()
+			defer s.mu.RUnlock()
+			live := make(map[string]float64)
+			for _, streamer := range s.streamers {
+				n := live[string(streamer.Platform)]
+				if streamer.IsLive {
+					n++
+				}
+				live[string(streamer.Platform)] = n
+			}
+			for platform, n := range live {
+				set(n, platform)
+			}
+		})
+
+	r.GaugeFunc("cxtv_platform_paused", "Whether a platform is paused by its circuit breaker (1) or queried normally (0).", []string{"platform"},
+		func(set func(float64, ...string)) {
+			for _, p := range s.GetPlatforms() {
+				paused := 0.0
+				if p.State != BreakerClosed {
+					paused = 1
+				}
+				set(paused, string(p.Platform))
+			}
+		})
+
+	r.GaugeFunc("cxtv_streamer_consecutive_errors", "Consecutive failed queries of a streamer.", []string{"streamer_id", "platform"},
+		func(set func(float64, ...string)) {
+			s.mu.RLock()
+			defer s.mu.RUnlock()
+			for id, streamer := range s.streamers {
+				set(float64(s.errorCounts[id]), id, string(streamer.Platform))
+			}
+		})
+
+	r.GaugeFunc("cxtv_streamer_last_success_age_seconds", "Seconds since the last successful query of a streamer.", []string{"streamer_id", "platform"},
+		func(set func(float64, ...string)) {
+			s.mu.RLock()
+			defer s.mu.RUnlock()
+			now := time.Now()
+			for id, streamer := range s.streamers {
+				if t, ok := s.lastSuccess[id]; ok {
+					set(now.Sub(t).Seconds(), id, string(streamer.Platform))
+				}
+			}
+		})
+}
+
+// Metrics returns the registry the service's metrics are kept in, for the
+// HTTP metrics to be added to and for serving /metrics.
+func (s *Service) Metrics() *metrics.Registry {
+	return s.registry
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+
+	"cxtv-alerts/internal/model"
+	"cxtv-alerts/internal/notify"
+	"cxtv-alerts/internal/webpush"
+)
+
+const defaultPushSubject = "https://github.com/posoo/cxtv-alerts"
+
+var (
+	ErrPushDisabled        = errors.New("web push is not enabled")
+	ErrInvalidSubscription = errors.New("invalid push subscription")
+	ErrUnknownStreamer     = errors.New("unknown streamer")
+)
+
+// initNotifiers registers the notification channels enabled in settings.
+func (s *Service) initNotifiers() {
+	if tg := s.settings.Telegram; tg != nil && tg.BotToken != "" {
+		eventTypes, err := notify.EventTypes(tg.Events, notify.EventLiveStart)
+		if err != nil {
+			slog.Warn("Skipping Telegram bot", "error", err)
+		} else {
+			s.telegram = notify.NewTelegramNotifier(tg.BotToken, tg.APIBase, s.db, s.getStreamer)
+			s.dispatcher.Register(notify.Filtered(s.telegram, notify.Filter{Events: eventTypes}))
+		}
+	}
+
+	for i, cfg := range s.settings.Webhooks {
+		n, err := notify.NewWebhook(cfg, s.settings.PublicURL)
+		if err != nil {
+			slog.Warn("Skipping webhook", "index", i, "error", err)
+			continue
+		}
+		s.dispatcher.Register(n)
+	}
+}
+
+// StartNotifiers starts the background loops of notifiers that receive
+// commands, such as the Telegram bot, until ctx is cancelled.
+func (s *Service) StartNotifiers(ctx context.Context) {
+	if s.telegram != nil {
+		s.loops.Add(1)
+		go func() {
+			defer s.loops.Done()
+			s.telegram.Run(ctx)
+		}()
+	}
+}
+
+// EnableWebPush loads (or creates) the VAPID key pair at keyPath and
+// registers the browser push notifier.
+func (s *Service) EnableWebPush(keyPath string) error {
+	subject := s.settings.PublicURL
+	if subject == "" {
+		subject = defaultPushSubject
+	}
+
+	vapid, err := webpush.LoadOrGenerateVAPID(keyPath, subject)
+	if err != nil {
+		return err
+	}
+	s.vapid = vapid
+	s.dispatcher.Register(notify.NewWebPushNotifier(vapid, s.db))
+	return nil
+}
+
+// PushPublicKey returns the VAPID public key browsers subscribe with.
+func (s *Service) PushPublicKey() (string, error) {
+	if s.vapid == nil {
+		return "", ErrPushDisabled
+	}
+	return s.vapid.PublicKey(), nil
+}
+
+// SubscribePush stores the streamers a browser wants to be notified about,
+// replacing its previous selection.
+func (s *Service) SubscribePush(sub model.PushSubscription, streamerIDs []string) error {
+	if s.vapid == nil {
+		return ErrPushDisabled
+	}
+	if u, err := url.Parse(sub.Endpoint); err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%w: endpoint must be an https URL", ErrInvalidSubscription)
+	}
+	if sub.Keys.P256dh == "" || sub.Keys.Auth == "" {
+		return fmt.Errorf("%w: missing keys", ErrInvalidSubscription)
+	}
+	for _, id := range streamerIDs {
+		if _, ok := s.getStreamer(id); !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownStreamer, id)
+		}
+	}
+	return s.db.SetPushSubscription(sub, streamerIDs)
+}
+
+// UnsubscribePush removes a browser push subscription.
+func (s *Service) UnsubscribePush(endpoint string) error {
+	return s.db.DeletePushSubscription(endpoint)
+}
+
+func (s *Service) getStreamer(id string) (model.Streamer, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	streamer, ok := s.streamers[id]
+	if !ok {
+		return model.Streamer{}, false
+	}
+	return *streamer, true
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"time"
+
+	"cxtv-alerts/internal/events"
+	"cxtv-alerts/internal/logging"
+	"cxtv-alerts/internal/model"
+)
+
+// configPollInterval is how often the config files are checked for changes
+const configPollInterval = 5 * time.Second
+
+// fileVersion identifies the contents of a config file on disk well enough
+// to notice edits without reading it.
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFile(path string) fileVersion {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileVersion{}
+	}
+	return fileVersion{modTime: info.ModTime(), size: info.Size()}
+}
+
+// current returns the active config and settings. Both are replaced as a
+// whole on reload, so callers may keep using them without holding s.mu.
+func (s *Service) current() (*model.Config, *model.Settings) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config, s.settings
+}
+
+// StartConfigWatcher reloads streamers.json and settings.json when either
+// file changes on disk or a value arrives on trigger (e.g. SIGHUP), until
+// ctx is cancelled.
+func (s *Service) StartConfigWatcher(ctx context.Context, trigger <-chan os.Signal) {
+	s.loops.Add(1)
+	go func() {
+		defer s.loops.Done()
+
+		ticker := time.NewTicker(configPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.reloadMu.Lock()
+				changed := statFile(s.configPath) != s.configMod || statFile(s.settingsPath) != s.settingsMod
+				s.reloadMu.Unlock()
+				if !changed {
+					continue
+				}
+				slog.Info("Config files changed, reloading")
+			case sig := <-trigger:
+				slog.Info("Reloading config on signal", "signal", sig)
+			case <-ctx.Done():
+				return
+			}
+
+			if err := s.Reload(); err != nil {
+				slog.Error("Config reload rejected, keeping the current config", "error", err)
+			}
+		}
+	}()
+}
+
+// Reload reads and validates both config files and applies them. If either
+// file is invalid nothing changes.
+func (s *Service) Reload() error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	// Record the versions first, so a rejected file is not retried until it
+	// is edited again
+	s.configMod = statFile(s.configPath)
+	s.settingsMod = statFile(s.settingsPath)
+
+	config, err := loadConfig(s.configPath)
+	if err != nil {
+		return fmt.Errorf("%s: %w", s.configPath, err)
+	}
+	settings, err := loadSettings(s.settingsPath)
+	if err != nil {
+		return fmt.Errorf("%s: %w", s.settingsPath, err)
+	}
+	if err := Validate(config, settings, s.configPath, s.settingsPath).Err(); err != nil {
+		return err
+	}
+	applySettingsDefaults(settings)
+
+	s.apply(config, settings)
+	return nil
+}
+
+// apply switches to a validated config: new streamers start being tracked,
+// removed ones have their open session closed, and the scanner picks up a
+// changed interval. Must hold s.reloadMu.
+func (s *Service) apply(config *model.Config, settings *model.Settings) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oldSettings := s.settings
+	wanted := make(map[string]model.StreamerConfig, len(config.Streamers))
+	for _, sc := range config.Streamers {
+		wanted[sc.ID] = sc
+	}
+
+	var added, removed, updated int
+	for id, streamer := range s.streamers {
+		sc, ok := wanted[id]
+		// A different room is a different stream, so it starts over
+		if !ok || !sameRoom(streamer, sc) {
+			s.removeStreamer(id)
+			if !ok {
+				removed++
+			}
+			continue
+		}
+		if streamer.Name != sc.Name || streamer.RoomURL != sc.LiveURL {
+			streamer.Name = sc.Name
+			streamer.RoomURL = sc.LiveURL
+			updated++
+		}
+	}
+	for _, sc := range config.Streamers {
+		if _, ok := s.streamers[sc.ID]; ok {
+			continue
+		}
+		s.addStreamer(sc)
+		s.events.Publish(events.Event{
+			Type:     events.StreamerAdded,
+			Streamer: *s.streamers[sc.ID],
+		})
+		added++
+	}
+
+	s.config = config
+	s.settings = settings
+	slog.Info("Config reloaded",
+		"streamers", len(s.streamers),
+		"added", added,
+		"removed", removed,
+		"updated", updated)
+
+	s.applyLogLevel(settings)
+
+	if settings.ScanIntervalMinutes != oldSettings.ScanIntervalMinutes ||
+		settings.LiveScanIntervalMinutes != oldSettings.LiveScanIntervalMinutes ||
+		settings.DormantScanIntervalMinutes != oldSettings.DormantScanIntervalMinutes ||
+		settings.DormantDays != oldSettings.DormantDays {
+		s.rescheduleAll()
+		slog.Info("Scan intervals changed",
+			"interval", time.Duration(settings.ScanIntervalMinutes)*time.Minute,
+			"live", time.Duration(settings.LiveScanIntervalMinutes)*time.Minute,
+			"dormant", time.Duration(settings.DormantScanIntervalMinutes)*time.Minute)
+	}
+
+	// Notifiers hold open connections and queues, rebuilding them at
+	// runtime is not supported
+	if !reflect.DeepEqual(settings.Telegram, oldSettings.Telegram) ||
+		!reflect.DeepEqual(settings.Webhooks, oldSettings.Webhooks) ||
+		settings.PublicURL != oldSettings.PublicURL {
+		slog.Warn("Notification settings changed, restart to apply them")
+	}
+}
+
+// applyLogLevel switches the log level to the one in settings, or back to
+// the one given on the command line if settings do not set one.
+func (s *Service) applyLogLevel(settings *model.Settings) {
+	level := s.baseLogLevel
+	if settings.LogLevel != "" {
+		// Validated before
+		level, _ = logging.ParseLevel(settings.LogLevel)
+	}
+	if level != logging.Level.Level() {
+		logging.Level.Set(level)
+		slog.Info("Log level changed", "level", level)
+	}
+}
+
+// sameRoom reports whether a tracked streamer still matches its config entry.
+func sameRoom(streamer *model.Streamer, sc model.StreamerConfig) bool {
+	return streamer.Platform == sc.Platform && streamer.RoomID == sc.RoomID
+}
+
+// removeStreamer stops tracking a streamer, ending its open session. Must
+// hold s.mu.
+func (s *Service) removeStreamer(id string) {
+	if sessionID, ok := s.sessions[id]; ok {
+		if err := s.db.EndSession(sessionID, time.Now(), model.EndReasonRemoved); err != nil {
+			slog.Error("Error ending session of removed streamer", "streamer_id", id, "session_id", sessionID, "error", err)
+		}
+	}
+
+	streamer := *s.streamers[id]
+	s.unschedule(id, streamer.Platform)
+	delete(s.streamers, id)
+	delete(s.sessions, id)
+	delete(s.errorCounts, id)
+	delete(s.lastSuccess, id)
+	delete(s.offline, id)
+	delete(s.ended, id)
+
+	s.events.Publish(events.Event{
+		Type:     events.StreamerRemoved,
+		Streamer: streamer,
+	})
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"cxtv-alerts/internal/crawler"
+	"cxtv-alerts/internal/model"
+)
+
+// ResolveStreamer turns a pasted room link into a streamer config that is
+// ready to be added, with a fresh ID and name and avatar filled in from the
+// platform. Rooms that are already tracked are rejected.
+func (s *Service) ResolveStreamer(ctx context.Context, rawURL string) (model.StreamerConfig, error) {
+	config, settings := s.current()
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(settings.RequestTimeoutSeconds)*time.Second)
+	defer cancel()
+	return resolveStreamer(ctx, s.crawlers, config.Streamers, rawURL)
+}
+
+// ResolveURL is ResolveStreamer for use without a running service, against
+// the streamers in the config file at configPath.
+func ResolveURL(ctx context.Context, configPath, rawURL string) (model.StreamerConfig, error) {
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return model.StreamerConfig{}, err
+	}
+	return resolveStreamer(ctx, crawler.All(), config.Streamers, rawURL)
+}
+
+func resolveStreamer(ctx context.Context, crawlers map[model.Platform]crawler.Crawler, streamers []model.StreamerConfig, rawURL string) (model.StreamerConfig, error) {
+	room, err := crawler.ParseRoomURL(rawURL)
+	if err != nil {
+		return model.StreamerConfig{}, fmt.Errorf("%w: %v", ErrInvalidStreamer, err)
+	}
+	for _, sc := range streamers {
+		if sc.Platform == room.Platform && sc.RoomID == room.RoomID {
+			return model.StreamerConfig{}, fmt.Errorf("%w: room is tracked as %s", ErrDuplicateStreamer, sc.ID)
+		}
+	}
+
+	sc, err := crawler.Resolve(ctx, crawlers, rawURL)
+	if err != nil {
+		return model.StreamerConfig{}, err
+	}
+	sc.ID = nextStreamerID(streamers, sc.Platform)
+	return sc, nil
+}
+
+// nextStreamerID continues the <platform>_<n> numbering of existing IDs.
+func nextStreamerID(streamers []model.StreamerConfig, platform model.Platform) string {
+	prefix := string(platform) + "_"
+	next := 1
+	for _, sc := range streamers {
+		n, err := strconv.Atoi(strings.TrimPrefix(sc.ID, prefix))
+		if err == nil && strings.HasPrefix(sc.ID, prefix) && n >= next {
+			next = n + 1
+		}
+	}
+	return prefix + strconv.Itoa(next)
+}
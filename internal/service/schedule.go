@@ -0,0 +1,392 @@
+package service
+
+import (
+	"container/heap"
+	"context"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"cxtv-alerts/internal/crawler"
+	"cxtv-alerts/internal/model"
+)
+
+const (
+	habitLookback = 60 * 24 * time.Hour // sessions considered for habitual start hours
+	habitSessions = 200                 // most recent sessions read for habits
+	habitLead     = 30 * time.Minute    // how long before a habitual start hour polling speeds up
+	habitMaxAge   = 24 * time.Hour      // habits are recomputed at least this often
+	skipDelay     = 5 * time.Second     // wait after a due streamer could not be queried
+)
+
+// habit summarises when a streamer usually goes live.
+type habit struct {
+	hours    [24]int   // sessions within habitLookback by local start hour
+	total    int       // sessions within habitLookback
+	lastLive time.Time // start of the latest session, zero if never live
+	updated  time.Time
+}
+
+// newHabit builds a habit from session start times, newest first.
+func newHabit(starts []time.Time, now time.Time) *habit {
+	h := &habit{updated: now}
+	if len(starts) > 0 {
+		h.lastLive = starts[0]
+	}
+	for _, t := range starts {
+		if now.Sub(t) > habitLookback {
+			break
+		}
+		h.hours[t.Local().Hour()]++
+		h.total++
+	}
+	return h
+}
+
+// habitual reports whether sessions often start in the hour of t: at least
+// 3 times and a fifth of all sessions in the lookback.
+func (h *habit) habitual(t time.Time) bool {
+	n := h.hours[t.Local().Hour()]
+	return n >= 3 && n*5 >= h.total
+}
+
+// nearStart reports whether t is in, or shortly before, a habitual start
+// hour.
+func (h *habit) nearStart(t time.Time) bool {
+	return h.habitual(t) || h.habitual(t.Add(habitLead))
+}
+
+// habitOf returns a streamer's habit, reading it from the database when
+// missing or outdated. Must hold s.mu.
+func (s *Service) habitOf(id string, now time.Time) *habit {
+	if h := s.habits[id]; h != nil && now.Sub(h.updated) < habitMaxAge {
+		return h
+	}
+	starts, err := s.db.GetSessionStarts(id, habitSessions)
+	if err != nil {
+		slog.Error("Error getting session start times", "streamer_id", id, "error", err)
+	}
+	h := newHabit(starts, now)
+	s.habits[id] = h
+	return h
+}
+
+// scanInterval is how long to wait before querying a streamer again:
+// shorter while it is live or about to go live at an hour it usually
+// does, longer once it has not been live for dormant_days. The reason is
+// for logging. Must hold s.mu.
+func (s *Service) scanInterval(id string, now time.Time) (time.Duration, string) {
+	settings := s.settings
+	if streamer := s.streamers[id]; streamer != nil && streamer.IsLive {
+		return time.Duration(settings.LiveScanIntervalMinutes) * time.Minute, "live"
+	}
+	h := s.habitOf(id, now)
+	dormantAfter := time.Duration(settings.DormantDays) * 24 * time.Hour
+	switch {
+	case h.nearStart(now):
+		return time.Duration(settings.LiveScanIntervalMinutes) * time.Minute, "habitual start"
+	case !h.lastLive.IsZero() && now.Sub(h.lastLive) > dormantAfter:
+		return time.Duration(settings.DormantScanIntervalMinutes) * time.Minute, "dormant"
+	default:
+		return time.Duration(settings.ScanIntervalMinutes) * time.Minute, "regular"
+	}
+}
+
+// dueStreamer is a streamer waiting in its platform's queue.
+type dueStreamer struct {
+	id        string
+	due       time.Time
+	lastQuery time.Time // zero if never queried
+	index     int       // position in the heap
+}
+
+// dueQueue is a min-heap of streamers by due time.
+type dueQueue []*dueStreamer
+
+func (q dueQueue) Len() int           { return len(q) }
+func (q dueQueue) Less(i, j int) bool { return q[i].due.Before(q[j].due) }
+
+func (q dueQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *dueQueue) Push(x any) {
+	item := x.(*dueStreamer)
+	item.index = len(*q)
+	*q = append(*q, item)
+}
+
+func (q *dueQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return item
+}
+
+// platformQueue holds a platform's streamers by due time, for the
+// platform's worker to query one at a time. Guarded by s.mu.
+type platformQueue struct {
+	due       dueQueue
+	byID      map[string]*dueStreamer
+	wake      chan struct{} // signalled when the queue changed
+	created   time.Time
+	firstPass bool      // every streamer due when the queue was created has been queried
+	progress  time.Time // when the worker last finished a query or had nothing due
+	passStart time.Time // when the worker started on the streamers due, zero between passes
+	passDue   time.Time // streamers due by then belong to the current pass
+}
+
+func newPlatformQueue() *platformQueue {
+	now := time.Now()
+	return &platformQueue{
+		byID:     make(map[string]*dueStreamer),
+		wake:     make(chan struct{}, 1),
+		created:  now,
+		progress: now,
+	}
+}
+
+// schedule adds a streamer or moves it to a new due time.
+func (q *platformQueue) schedule(id string, due, lastQuery time.Time) {
+	if item, ok := q.byID[id]; ok {
+		item.due = due
+		item.lastQuery = lastQuery
+		heap.Fix(&q.due, item.index)
+	} else {
+		item := &dueStreamer{id: id, due: due, lastQuery: lastQuery}
+		heap.Push(&q.due, item)
+		q.byID[id] = item
+	}
+	q.notify()
+}
+
+func (q *platformQueue) remove(id string) {
+	if item, ok := q.byID[id]; ok {
+		heap.Remove(&q.due, item.index)
+		delete(q.byID, id)
+		q.notify()
+	}
+}
+
+// head returns the streamer due first, or nil.
+func (q *platformQueue) head() *dueStreamer {
+	if len(q.due) == 0 {
+		return nil
+	}
+	return q.due[0]
+}
+
+// observe records the state the worker found the queue in before picking
+// its next streamer. Nothing being due, or the platform being paused,
+// counts as keeping up. It returns the duration of the pass just completed,
+// i.e. of querying every streamer that was due when the worker last found
+// work, or 0. Must hold s.mu.
+func (q *platformQueue) observe(now time.Time, paused bool) time.Duration {
+	head := q.head()
+	idle := head == nil || paused || head.due.After(now)
+	if idle {
+		q.progress = now
+	}
+	// Queried streamers are due an interval after their query, so once the
+	// head is due after the queue was created, everyone due then was queried
+	if idle || head.due.After(q.created) {
+		q.firstPass = true
+	}
+
+	switch {
+	case paused:
+		// A pause says nothing about how long querying takes
+		q.passStart = time.Time{}
+	case q.passStart.IsZero():
+		if !idle {
+			q.passStart, q.passDue = now, now
+		}
+	case head == nil || head.due.After(q.passDue):
+		pass := now.Sub(q.passStart)
+		q.passStart = time.Time{}
+		return pass
+	}
+	return 0
+}
+
+// finished records that the worker finished a query. Must hold s.mu.
+func (q *platformQueue) finished(now time.Time) {
+	q.progress = now
+}
+
+// behind returns why the platform's worker is not keeping up, or "" if it
+// is. A worker with nothing due, or paused until pausedUntil, is keeping up
+// however long it has been asleep. Otherwise it is behind once it has gone
+// maxAge without finishing a query since the head fell due or the pause
+// ended. A platform with more streamers than it can query in one interval
+// is therefore only behind when the worker stops finishing queries, not
+// while its backlog stays bounded. Must hold s.mu.
+func (q *platformQueue) behind(now, pausedUntil time.Time, maxAge time.Duration) string {
+	if !q.firstPass {
+		return "first scan has not completed"
+	}
+	head := q.head()
+	if head == nil || head.due.After(now) || pausedUntil.After(now) {
+		return ""
+	}
+	since := q.progress
+	for _, t := range []time.Time{head.due, pausedUntil} {
+		if t.After(since) {
+			since = t
+		}
+	}
+	if now.Sub(since) > maxAge {
+		return "no query finished since " + since.Format("2006-01-02 15:04:05")
+	}
+	return ""
+}
+
+func (q *platformQueue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// schedule queues a streamer for its next query, due a scan interval after
+// lastQuery (immediately if zero). Must hold s.mu.
+func (s *Service) schedule(id string, platform model.Platform, lastQuery time.Time) (time.Duration, string) {
+	q := s.queues[platform]
+	if q == nil {
+		// No crawler, rejected by validation
+		return 0, ""
+	}
+	interval, reason := s.scanInterval(id, time.Now())
+	due := time.Time{}
+	if !lastQuery.IsZero() {
+		due = lastQuery.Add(interval)
+	}
+	q.schedule(id, due, lastQuery)
+	return interval, reason
+}
+
+// rescheduleAll recomputes every streamer's due time, after the scan
+// intervals changed. Must hold s.mu.
+func (s *Service) rescheduleAll() {
+	for platform, q := range s.queues {
+		for id, item := range q.byID {
+			s.schedule(id, platform, item.lastQuery)
+		}
+	}
+}
+
+// unschedule removes a streamer from its platform's queue. Must hold s.mu.
+func (s *Service) unschedule(id string, platform model.Platform) {
+	if q := s.queues[platform]; q != nil {
+		q.remove(id)
+	}
+	delete(s.habits, id)
+}
+
+// streamerConfig returns the config entry of a tracked streamer. Must hold
+// s.mu.
+func (s *Service) streamerConfig(id string) (model.StreamerConfig, bool) {
+	for _, sc := range s.config.Streamers {
+		if sc.ID == id {
+			return sc, true
+		}
+	}
+	return model.StreamerConfig{}, false
+}
+
+// runPlatform queries a platform's streamers one at a time as they become
+// due, with a random platform delay between requests, until ctx is
+// cancelled. While the platform is paused by its breaker, due streamers
+// wait for the pause to end.
+func (s *Service) runPlatform(ctx context.Context, platform model.Platform, q *platformQueue) {
+	c := s.crawlers[platform]
+	var notBefore time.Time // end of the delay after the previous request
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		s.mu.Lock()
+		now := time.Now()
+		head := q.head()
+		var next time.Time
+		paused := false
+		if head != nil {
+			next = head.due
+			if until := s.pausedUntil(platform); until.After(now) {
+				next, paused = until, true
+			}
+			if notBefore.After(next) {
+				next = notBefore
+			}
+		}
+		pass := q.observe(now, paused)
+		s.mu.Unlock()
+		if pass > 0 {
+			s.metrics.cycles.Observe(pass.Seconds(), string(platform))
+		}
+
+		if head == nil || next.After(now) {
+			var wait <-chan time.Time
+			if head != nil {
+				timer.Reset(next.Sub(now))
+				wait = timer.C
+			}
+			select {
+			case <-wait:
+			case <-q.wake:
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+
+		if !s.query(ctx, platform, q, c, head) {
+			// Removed by a reload or refused by the breaker, wait a little
+			// rather than spin on the same head
+			notBefore = time.Now().Add(skipDelay)
+			continue
+		}
+		_, settings := s.current()
+		minDelay, maxDelay := settings.PlatformDelayMinSeconds, settings.PlatformDelayMaxSeconds
+		notBefore = time.Now().Add(time.Duration(minDelay+rand.Intn(maxDelay-minDelay+1)) * time.Second)
+	}
+}
+
+// query queries a due streamer and queues its next query. It reports
+// whether a request was sent.
+func (s *Service) query(ctx context.Context, platform model.Platform, q *platformQueue, c crawler.Crawler, item *dueStreamer) bool {
+	// Look the streamer up first: once the breaker hands out its probe, the
+	// probe must be sent or cancelled
+	s.mu.RLock()
+	sc, ok := s.streamerConfig(item.id)
+	due := item.due
+	s.mu.RUnlock()
+	if !ok || !s.allowQuery(slog.Default(), platform) {
+		return false
+	}
+
+	_, settings := s.current()
+	started := time.Now()
+	if !due.IsZero() {
+		s.metrics.lag.Observe(started.Sub(due).Seconds(), string(platform))
+	}
+	s.scanStreamer(ctx, sc, c, time.Duration(settings.RequestTimeoutSeconds)*time.Second)
+	if ctx.Err() != nil {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastQuery = time.Now()
+	q.finished(s.lastQuery)
+	// Removed or replaced by a config reload during the query
+	if q.byID[item.id] != item {
+		return true
+	}
+	interval, reason := s.schedule(item.id, platform, started)
+	slog.Debug("Next query scheduled", append(streamerAttrs(sc), "in", interval, "reason", reason)...)
+	return true
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"cxtv-alerts/internal/model"
+)
+
+func TestHabit(t *testing.T) {
+	now := time.Date(2024, 6, 30, 12, 0, 0, 0, time.Local)
+	day := 24 * time.Hour
+	var starts []time.Time
+	for i := 1; i <= 10; i++ {
+		starts = append(starts, time.Date(2024, 6, 30-i, 20, 15, 0, 0, time.Local))
+	}
+	starts = append(starts,
+		time.Date(2024, 6, 10, 9, 0, 0, 0, time.Local),
+		now.Add(-200*day), // outside the lookback, only counts as the last time live
+	)
+
+	h := newHabit(starts, now)
+	if h.total != 11 || !h.lastLive.Equal(starts[0]) {
+		t.Fatalf("total %d, last live %s", h.total, h.lastLive)
+	}
+	tests := []struct {
+		at   time.Time
+		near bool
+	}{
+		{time.Date(2024, 7, 1, 19, 0, 0, 0, time.Local), false},
+		{time.Date(2024, 7, 1, 19, 40, 0, 0, time.Local), true},
+		{time.Date(2024, 7, 1, 20, 50, 0, 0, time.Local), true},
+		{time.Date(2024, 7, 1, 21, 10, 0, 0, time.Local), false},
+		{time.Date(2024, 7, 1, 9, 0, 0, 0, time.Local), false}, // a single session is no habit
+	}
+	for _, tt := range tests {
+		if got := h.nearStart(tt.at); got != tt.near {
+			t.Errorf("nearStart(%s) = %v, want %v", tt.at.Format("15:04"), got, tt.near)
+		}
+	}
+
+	if h := newHabit(nil, now); !h.lastLive.IsZero() || h.nearStart(now) {
+		t.Error("habit without sessions")
+	}
+}
+
+func TestPlatformQueue(t *testing.T) {
+	q := newPlatformQueue()
+	base := time.Now()
+	q.schedule("a", base.Add(3*time.Minute), time.Time{})
+	q.schedule("b", base.Add(1*time.Minute), time.Time{})
+	q.schedule("c", base.Add(2*time.Minute), time.Time{})
+	if q.head().id != "b" {
+		t.Fatalf("head %s, want b", q.head().id)
+	}
+
+	q.schedule("b", base.Add(5*time.Minute), base)
+	q.remove("c")
+	if q.head().id != "a" {
+		t.Fatalf("head %s after reschedule, want a", q.head().id)
+	}
+	q.remove("a")
+	q.remove("b")
+	if q.head() != nil || len(q.byID) != 0 {
+		t.Fatal("queue not empty")
+	}
+}
+
+func TestQueryRemovedStreamerKeepsProbe(t *testing.T) {
+	s := newTestService(t, testStreamers)
+	p := model.PlatformBilibili
+	s.breakers[p] = &breaker{state: BreakerOpen, openUntil: time.Now()}
+
+	// Removed by a reload after the worker picked it as the head
+	item := &dueStreamer{id: "bilibili_removed"}
+	if s.query(context.Background(), p, s.queues[p], &fakeCrawler{}, item) {
+		t.Fatal("query sent for a removed streamer")
+	}
+	if state := s.breakers[p].state; state != BreakerOpen {
+		t.Fatalf("breaker %s after skipped query, want open", state)
+	}
+	if !s.allowQuery(slog.Default(), p) {
+		t.Fatal("probe not available for the next streamer")
+	}
+}
+
+// TestReadinessOverloadedPlatform simulates a worker on a platform with
+// more streamers than it can query in one interval: its backlog never
+// empties, but it keeps finishing queries and so stays ready.
+func TestReadinessOverloadedPlatform(t *testing.T) {
+	const (
+		streamers = 25
+		interval  = 5 * time.Minute
+		perQuery  = 13 * time.Second // average platform delay plus request time
+	)
+	maxAge := staleScanFactor * interval
+	q := newPlatformQueue()
+	now := q.created
+	for i := 0; i < streamers; i++ {
+		// Restored from the database, all overdue at startup
+		q.schedule(fmt.Sprint(i), now.Add(-time.Duration(i)*time.Minute), now.Add(-interval))
+	}
+
+	firstPassDone := time.Time{}
+	for end := now.Add(3 * time.Hour); now.Before(end); {
+		q.observe(now, false)
+		if reason := q.behind(now, time.Time{}, maxAge); reason != "" && !firstPassDone.IsZero() {
+			t.Fatalf("not ready after %s: %s", now.Sub(q.created), reason)
+		} else if reason == "" && firstPassDone.IsZero() {
+			firstPassDone = now
+		}
+
+		head := q.head()
+		if head.due.After(now) {
+			t.Fatalf("queue caught up after %s, the platform is not overloaded", now.Sub(q.created))
+		}
+		started := now
+		now = now.Add(perQuery)
+		q.finished(now)
+		q.schedule(head.id, started.Add(interval), started)
+	}
+	if firstPassDone.IsZero() || firstPassDone.Sub(q.created) > streamers*perQuery+perQuery {
+		t.Fatalf("first pass completed after %s", firstPassDone.Sub(q.created))
+	}
+
+	// A worker that stops finishing queries falls behind
+	q.observe(now, false)
+	if reason := q.behind(now.Add(maxAge+time.Second), time.Time{}, maxAge); reason == "" {
+		t.Fatal("ready although no query finished for longer than the limit")
+	}
+}
+
+// TestReadinessAsleep checks readiness while the worker sleeps, long after it
+// last looked at its queue.
+func TestReadinessAsleep(t *testing.T) {
+	const interval = 5 * time.Minute
+	maxAge := staleScanFactor * interval
+
+	// Only dormant streamers, the next one due in an hour
+	q := newPlatformQueue()
+	start := q.created
+	q.schedule("dormant", start.Add(time.Hour), start)
+	q.observe(start, false)
+	for _, after := range []time.Duration{20 * time.Minute, 50 * time.Minute, time.Hour + maxAge} {
+		if reason := q.behind(start.Add(after), time.Time{}, maxAge); reason != "" {
+			t.Errorf("dormant queue %s into its sleep: %s", after, reason)
+		}
+	}
+	if reason := q.behind(start.Add(time.Hour+maxAge+time.Second), time.Time{}, maxAge); reason == "" {
+		t.Error("ready although the worker did not wake up for the due streamer")
+	}
+
+	// An empty queue
+	q = newPlatformQueue()
+	q.observe(q.created, false)
+	if reason := q.behind(q.created.Add(2*time.Hour), time.Time{}, maxAge); reason != "" {
+		t.Errorf("empty queue: %s", reason)
+	}
+
+	// A platform paused for two hours with streamers overdue. The breaker
+	// reports the end of the pause until the probe goes out.
+	q = newPlatformQueue()
+	start = q.created
+	q.schedule("a", start, time.Time{})
+	pausedUntil := start.Add(2 * time.Hour)
+	q.observe(start, true)
+	for _, at := range []time.Time{start.Add(30 * time.Minute), pausedUntil.Add(maxAge)} {
+		if reason := q.behind(at, pausedUntil, maxAge); reason != "" {
+			t.Errorf("paused platform %s in: %s", at.Sub(start), reason)
+		}
+	}
+	if reason := q.behind(pausedUntil.Add(maxAge+time.Second), pausedUntil, maxAge); reason == "" {
+		t.Error("ready although no query finished long after the pause ended")
+	}
+}
+
+func TestPlatformQueuePass(t *testing.T) {
+	q := newPlatformQueue()
+	now := q.created
+	for _, id := range []string{"a", "b", "c"} {
+		q.schedule(id, now, time.Time{})
+	}
+	q.schedule("later", now.Add(time.Hour), now)
+
+	// Streamers falling due during the pass belong to the next one
+	var passes []time.Duration
+	for i := 0; i < 4; i++ {
+		if pass := q.observe(now, false); pass > 0 {
+			passes = append(passes, pass)
+		}
+		head := q.head()
+		if head.id == "later" {
+			break
+		}
+		started := now
+		now = now.Add(10 * time.Second)
+		q.schedule(head.id, started.Add(5*time.Minute), started)
+		if i == 0 {
+			q.schedule("new", now, time.Time{})
+		}
+	}
+	if len(passes) != 1 || passes[0] != 30*time.Second {
+		t.Fatalf("passes %v, want [30s]", passes)
+	}
+
+	// A pause abandons the pass
+	q.schedule("x", now, time.Time{})
+	q.observe(now, false)
+	q.observe(now.Add(time.Minute), true)
+	q.remove("x")
+	if pass := q.observe(now.Add(2*time.Minute), false); pass != 0 {
+		t.Fatalf("pass of %s recorded across a pause", pass)
+	}
+}
@@ -1,106 +1,241 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"math/rand"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"cxtv-alerts/internal/crawler"
 	"cxtv-alerts/internal/database"
+	"cxtv-alerts/internal/events"
+	"cxtv-alerts/internal/logging"
+	"cxtv-alerts/internal/metrics"
 	"cxtv-alerts/internal/model"
+	"cxtv-alerts/internal/notify"
+	"cxtv-alerts/internal/webpush"
 )
 
+// eventHistorySize is how many recent events SSE clients can resume from
+const eventHistorySize = 256
+
+// offlineState tracks offline results for a streamer whose session is still
+// open, until enough consecutive confirmations end it.
+type offlineState struct {
+	count int
+	since time.Time // first offline observation, used as the session end
+}
+
+// endedSession is a session this run ended after confirming the streamer
+// offline.
+type endedSession struct {
+	id        int64
+	confirmed time.Time // when the last offline confirmation came in
+}
+
+// Options locates the files the service reads and writes.
+type Options struct {
+	ConfigPath   string // streamers.json
+	SettingsPath string // settings.json
+	AvatarDir    string // downloaded avatars, served under /static/avatars/
+	NoNotify     bool   // register no notifiers, events only reach the event stream
+}
+
 type Service struct {
-	db          *database.DB
-	crawlers    map[model.Platform]crawler.Crawler
-	config      *model.Config
-	settings    *model.Settings
-	streamers   map[string]*model.Streamer
-	sessions    map[string]int64 // streamerID -> sessionID
-	errorCounts map[string]int   // streamerID -> consecutive error count
-	mu          sync.RWMutex
+	db               *database.DB
+	crawlers         map[model.Platform]crawler.Crawler
+	configPath       string
+	settingsPath     string
+	avatarDir        string
+	config           *model.Config   // replaced, never modified, on reload
+	settings         *model.Settings // replaced, never modified, on reload
+	configMod        fileVersion     // versions of the files config and settings were loaded from
+	settingsMod      fileVersion
+	reloadMu         sync.Mutex
+	streamers        map[string]*model.Streamer
+	sessions         map[string]int64 // streamerID -> sessionID
+	errorCounts      map[string]int   // streamerID -> consecutive error count
+	lastSuccess      map[string]time.Time
+	platformFailures map[model.Platform]int // consecutive failed queries per platform
+	breakers         map[model.Platform]*breaker
+	queues           map[model.Platform]*platformQueue // streamers by next due time
+	habits           map[string]*habit
+	lastQuery        time.Time     // when the scanner last completed a query
+	scanSeq          atomic.Uint64 // numbers scans for the scan_id log attribute
+	baseLogLevel     slog.Level    // from the command line, used when settings do not set one
+	offline          map[string]*offlineState
+	ended            map[string]endedSession // streamerID -> last session ended by offline confirmation
+	dispatcher       *notify.Dispatcher
+	events           *events.Hub
+	telegram         *notify.TelegramNotifier
+	vapid            *webpush.VAPID
+	registry         *metrics.Registry
+	metrics          scanMetrics
+	loops            sync.WaitGroup // background loops, waited for by Shutdown
+	mu               sync.RWMutex
 }
 
-func New(db *database.DB, configPath, settingsPath string) (*Service, error) {
+func New(db *database.DB, opts Options) (*Service, error) {
+	configPath, settingsPath := opts.ConfigPath, opts.SettingsPath
+	configMod := statFile(configPath)
+	settingsMod := statFile(settingsPath)
+
 	config, err := loadConfig(configPath)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%s: %s", configPath, describeLoadError(err))
 	}
 
 	settings, err := loadSettings(settingsPath)
-	if err != nil {
-		log.Printf("Warning: failed to load settings, using defaults: %v", err)
+	if errors.Is(err, os.ErrNotExist) {
+		slog.Warn("Settings file not found, using default settings", "path", settingsPath)
 		settings = &model.Settings{
 			ScanIntervalMinutes:     5,
 			PlatformDelayMinSeconds: 5,
 			PlatformDelayMaxSeconds: 20,
 		}
+	} else if err != nil {
+		return nil, fmt.Errorf("%s: %s", settingsPath, describeLoadError(err))
+	}
+
+	if problems := Validate(config, settings, configPath, settingsPath); len(problems) > 0 {
+		for _, p := range problems {
+			slog.Error("Config problem", "file", p.File, "path", p.Path, "problem", p.Message)
+		}
+		return nil, fmt.Errorf("invalid configuration (%d problems)", len(problems))
 	}
+	applySettingsDefaults(settings)
 
 	s := &Service{
-		db:          db,
-		config:      config,
-		settings:    settings,
-		streamers:   make(map[string]*model.Streamer),
-		sessions:    make(map[string]int64),
-		errorCounts: make(map[string]int),
-		crawlers: map[model.Platform]crawler.Crawler{
-			model.PlatformBilibili: crawler.NewBilibiliCrawler(),
-			model.PlatformDouyu:    crawler.NewDouyuCrawler(),
-			model.PlatformDouyin:   crawler.NewDouyinCrawler(),
-			model.PlatformKuaishou: crawler.NewKuaishouCrawler(),
-			model.PlatformCC163:    crawler.NewCC163Crawler(),
-			model.PlatformWeibo:    crawler.NewWeiboCrawler(),
-		},
-	}
-
-	// Initialize streamers from config
+		db:               db,
+		configPath:       configPath,
+		settingsPath:     settingsPath,
+		avatarDir:        opts.AvatarDir,
+		config:           config,
+		settings:         settings,
+		configMod:        configMod,
+		settingsMod:      settingsMod,
+		streamers:        make(map[string]*model.Streamer),
+		sessions:         make(map[string]int64),
+		errorCounts:      make(map[string]int),
+		lastSuccess:      make(map[string]time.Time),
+		platformFailures: make(map[model.Platform]int),
+		breakers:         make(map[model.Platform]*breaker),
+		queues:           make(map[model.Platform]*platformQueue),
+		habits:           make(map[string]*habit),
+		offline:          make(map[string]*offlineState),
+		ended:            make(map[string]endedSession),
+		dispatcher:       notify.NewDispatcher(),
+		events:           events.NewHub(eventHistorySize),
+		crawlers:         crawler.All(),
+		registry:         metrics.NewRegistry(),
+	}
+	s.initMetrics()
+	for platform := range s.crawlers {
+		s.queues[platform] = newPlatformQueue()
+	}
+
+	// Initialize streamers from config and restore their status from database
 	for _, sc := range config.Streamers {
-		s.streamers[sc.ID] = &model.Streamer{
-			ID:       sc.ID,
-			Name:     sc.Name,
-			Platform: sc.Platform,
-			RoomID:   sc.RoomID,
-			Avatar:   sc.Avatar,
-			RoomURL:  sc.LiveURL,
-			IsLive:   false,
-		}
+		s.addStreamer(sc)
 	}
 
-	// Restore status from database
-	for id := range s.streamers {
-		// Restore active sessions
-		if session, err := db.GetActiveSession(id); err == nil && session != nil {
+	// Load local avatars
+	s.loadLocalAvatars()
+
+	if !opts.NoNotify {
+		s.initNotifiers()
+	}
+
+	return s, nil
+}
+
+// addStreamer starts tracking a configured streamer, restoring its status
+// and open session from the database. Must hold s.mu (or run before the
+// service is shared).
+func (s *Service) addStreamer(sc model.StreamerConfig) {
+	id := sc.ID
+	s.streamers[id] = &model.Streamer{
+		ID:       sc.ID,
+		Name:     sc.Name,
+		Platform: sc.Platform,
+		RoomID:   sc.RoomID,
+		Avatar:   sc.Avatar,
+		RoomURL:  sc.LiveURL,
+		IsLive:   false,
+	}
+
+	// Restore active sessions, closing those left open by a long outage
+	closed := false
+	if session, err := s.db.GetActiveSession(id); err == nil && session != nil {
+		if closed = s.closeStaleSession(id, session); !closed {
 			s.sessions[id] = session.ID
 			s.streamers[id].IsLive = true
 			s.streamers[id].StartTime = session.StartTime.Format("2006-01-02 15:04:05")
 		}
+	}
 
-		// Restore last query time and cached status
-		if lastTime, lastFailed, isLive, title, viewerCount, avatarLocal, err := db.GetStreamerStatus(id); err == nil {
-			if lastTime != nil {
-				s.streamers[id].LastQueryTime = lastTime.Format("2006-01-02 15:04:05")
-			}
-			s.streamers[id].LastQueryFailed = lastFailed
-			if avatarLocal != "" {
-				s.streamers[id].AvatarLocal = "/static/avatars/" + avatarLocal
-			}
-			// Only use cached status if we don't have active session info
-			if _, hasSession := s.sessions[id]; !hasSession {
-				s.streamers[id].IsLive = isLive
-				s.streamers[id].Title = title
-				s.streamers[id].ViewerCount = viewerCount
-			}
+	if lastSuccess, err := s.db.GetLastSuccessTime(id); err == nil && lastSuccess != nil {
+		s.lastSuccess[id] = *lastSuccess
+	}
+
+	// Restore last query time and cached status
+	var lastQuery time.Time
+	if lastTime, lastError, isLive, title, viewerCount, avatarLocal, err := s.db.GetStreamerStatus(id); err == nil {
+		if lastTime != nil {
+			s.streamers[id].LastQueryTime = lastTime.Format("2006-01-02 15:04:05")
+			lastQuery = *lastTime
+		}
+		s.streamers[id].LastError = lastError
+		if avatarLocal != "" {
+			s.streamers[id].AvatarLocal = "/static/avatars/" + avatarLocal
+		}
+		// Only use cached status if we don't have active session info
+		if _, hasSession := s.sessions[id]; !hasSession && !closed {
+			s.streamers[id].IsLive = isLive
+			s.streamers[id].Title = title
+			s.streamers[id].ViewerCount = viewerCount
 		}
 	}
 
-	// Load local avatars
-	s.loadLocalAvatars()
+	s.schedule(id, sc.Platform, lastQuery)
+}
 
-	return s, nil
+// closeStaleSession ends a session left open by the previous run if the
+// streamer has not been successfully queried for longer than the downtime
+// threshold. We cannot know when such a broadcast really ended, so the
+// session is closed at the last time it was seen and marked as such.
+func (s *Service) closeStaleSession(streamerID string, session *model.LiveSession) bool {
+	lastSuccess, err := s.db.GetLastSuccessTime(streamerID)
+	if err != nil {
+		slog.Error("Error getting last success time", "streamer_id", streamerID, "error", err)
+		return false
+	}
+
+	lastSeen := session.StartTime
+	if lastSuccess != nil && lastSuccess.After(lastSeen) {
+		lastSeen = *lastSuccess
+	}
+	threshold := time.Duration(s.settings.DowntimeThresholdMinutes) * time.Minute
+	if time.Since(lastSeen) <= threshold {
+		return false
+	}
+
+	if err := s.db.EndSession(session.ID, lastSeen, model.EndReasonDowntime); err != nil {
+		slog.Error("Error closing stale session", "streamer_id", streamerID, "error", err)
+		return false
+	}
+	slog.Info("Closed stale session at last seen time",
+		"streamer_id", streamerID,
+		"session_id", session.ID,
+		"last_seen", lastSeen.Format("2006-01-02 15:04:05"),
+		"unseen_for", time.Since(lastSeen).Round(time.Minute))
+	return true
 }
 
 func loadConfig(path string) (*model.Config, error) {
@@ -131,119 +266,304 @@ func loadSettings(path string) (*model.Settings, error) {
 	return &settings, nil
 }
 
-func (s *Service) StartScanner() {
-	interval := time.Duration(s.settings.ScanIntervalMinutes) * time.Minute
-	log.Printf("Scanner started with interval: %v", interval)
+// applySettingsDefaults fills in settings that were added after the
+// original settings.json format and may be missing from existing files.
+func applySettingsDefaults(settings *model.Settings) {
+	if settings.OfflineConfirmations <= 0 {
+		settings.OfflineConfirmations = 2
+	}
+	if settings.SessionMergeGraceMinutes <= 0 {
+		settings.SessionMergeGraceMinutes = 10
+	}
+	if settings.DowntimeThresholdMinutes <= 0 {
+		settings.DowntimeThresholdMinutes = 3 * settings.ScanIntervalMinutes
+	}
+	if settings.RequestTimeoutSeconds <= 0 {
+		settings.RequestTimeoutSeconds = 30
+	}
+	if settings.LiveScanIntervalMinutes <= 0 {
+		settings.LiveScanIntervalMinutes = max(1, settings.ScanIntervalMinutes/2)
+	}
+	if settings.DormantScanIntervalMinutes <= 0 {
+		settings.DormantScanIntervalMinutes = 12 * settings.ScanIntervalMinutes
+	}
+	if settings.DormantDays <= 0 {
+		settings.DormantDays = 90
+	}
+	if settings.BreakerThreshold <= 0 {
+		settings.BreakerThreshold = 3
+	}
+	if settings.BreakerBackoffMinutes <= 0 {
+		settings.BreakerBackoffMinutes = 15
+	}
+	if settings.BreakerMaxBackoffMinutes <= 0 {
+		settings.BreakerMaxBackoffMinutes = 8 * 60
+	}
+}
 
-	// Do an immediate scan
-	go s.scan()
+// StartScanner starts a worker per platform that queries each streamer
+// whenever it is due, until ctx is cancelled. Cancelling ctx also aborts
+// queries in progress.
+func (s *Service) StartScanner(ctx context.Context) {
+	slog.Info("Scanner started", "platforms", len(s.queues))
 
-	ticker := time.NewTicker(interval)
+	s.loops.Add(1)
 	go func() {
-		for range ticker.C {
-			s.scan()
+		defer s.loops.Done()
+
+		var wg sync.WaitGroup
+		for platform, q := range s.queues {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.runPlatform(ctx, platform, q)
+			}()
 		}
+		wg.Wait()
+		slog.Info("Scanner stopped")
 	}()
 }
 
-func (s *Service) scan() {
-	log.Println("Starting scan...")
+// Shutdown waits for the background loops to stop (their context must be
+// cancelled first), delivers queued notifications and writes the in-memory
+// streamer state to the database. Open sessions stay open, so a quick
+// restart continues them.
+func (s *Service) Shutdown() {
+	s.loops.Wait()
+	s.dispatcher.Close()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for id, streamer := range s.streamers {
+		if err := s.db.SaveStreamerState(id, streamer.IsLive, streamer.Title, streamer.ViewerCount); err != nil {
+			slog.Error("Error saving streamer state", "streamer_id", id, "error", err)
+		}
+	}
+	slog.Info("Service state saved")
+}
+
+// ScanOnce queries every streamer once, regardless of when it is due, and
+// returns the IDs of those it queried; streamers of a paused platform are
+// skipped. Cancelling ctx aborts in-flight requests and pending delays.
+func (s *Service) ScanOnce(ctx context.Context) map[string]bool {
+	logger := slog.With("scan_id", s.scanSeq.Add(1))
+	ctx = logging.NewContext(ctx, logger)
+	logger.Info("Starting scan")
+	started := time.Now()
+
+	config, settings := s.current()
 
 	// Group streamers by platform
 	platformStreamers := make(map[model.Platform][]model.StreamerConfig)
-	for _, sc := range s.config.Streamers {
+	for _, sc := range config.Streamers {
 		platformStreamers[sc.Platform] = append(platformStreamers[sc.Platform], sc)
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, scanDeadline(settings, platformStreamers))
+	defer cancel()
+
 	var wg sync.WaitGroup
+	var mu sync.Mutex
+	queried := make(map[string]bool)
 
 	// Scan each platform in parallel, but within each platform scan sequentially with random delay
 	for platform, streamers := range platformStreamers {
 		c, ok := s.crawlers[platform]
 		if !ok {
-			log.Printf("No crawler for platform: %s", platform)
+			logger.Warn("No crawler for platform", "platform", platform)
 			continue
 		}
 
 		wg.Add(1)
 		go func(p model.Platform, scs []model.StreamerConfig, cr crawler.Crawler) {
 			defer wg.Done()
-			s.scanPlatform(p, scs, cr)
+			ids := s.scanPlatform(ctx, settings, p, scs, cr)
+			mu.Lock()
+			defer mu.Unlock()
+			for _, id := range ids {
+				queried[id] = true
+			}
 		}(platform, streamers, c)
 	}
 
 	wg.Wait()
-	log.Println("Scan complete")
+	if err := ctx.Err(); err != nil {
+		logger.Warn("Scan aborted", "duration", time.Since(started), "error", err)
+		return queried
+	}
+	logger.Info("Scan complete", "duration", time.Since(started))
+	return queried
 }
 
-func (s *Service) scanPlatform(platform model.Platform, streamers []model.StreamerConfig, c crawler.Crawler) {
-	scanInterval := time.Duration(s.settings.ScanIntervalMinutes) * time.Minute
-	minDelay := s.settings.PlatformDelayMinSeconds
-	maxDelay := s.settings.PlatformDelayMaxSeconds
-
-	for i, sc := range streamers {
-		// Check if we should skip this streamer based on last query time
-		lastQueryTime, err := s.db.GetLastQueryTime(sc.ID)
-		if err != nil {
-			log.Printf("Error getting last query time for %s: %v", sc.Name, err)
-		} else if lastQueryTime != nil {
-			elapsed := time.Since(*lastQueryTime)
-			if elapsed < scanInterval {
-				// Skip this streamer, was queried recently
-				continue
-			}
+// scanDeadline bounds a whole scan by the slowest platform's worst case:
+// every request timing out plus the longest delay between requests. Only a
+// scan stuck outside of the crawlers ever reaches it.
+func scanDeadline(settings *model.Settings, platformStreamers map[model.Platform][]model.StreamerConfig) time.Duration {
+	perStreamer := time.Duration(settings.RequestTimeoutSeconds+settings.PlatformDelayMaxSeconds) * time.Second
+	deadline := time.Duration(settings.ScanIntervalMinutes) * time.Minute
+	for _, scs := range platformStreamers {
+		if d := time.Duration(len(scs)) * perStreamer; d > deadline {
+			deadline = d
 		}
+	}
+	return deadline
+}
 
-		// Scan the streamer
-		s.scanStreamer(sc, c)
+// scanPlatform queries a platform's streamers in turn and returns the IDs
+// of those it queried.
+func (s *Service) scanPlatform(ctx context.Context, settings *model.Settings, platform model.Platform, streamers []model.StreamerConfig, c crawler.Crawler) []string {
+	requestTimeout := time.Duration(settings.RequestTimeoutSeconds) * time.Second
+	minDelay := settings.PlatformDelayMinSeconds
+	maxDelay := settings.PlatformDelayMaxSeconds
+
+	logger := logging.FromContext(ctx)
+	var queried []string
+	for _, sc := range streamers {
+		if ctx.Err() != nil {
+			return queried
+		}
+
+		// A paused platform skips the rest of the scan
+		if !s.allowQuery(logger, platform) {
+			logger.Debug("Platform paused, skipping", "platform", platform)
+			return queried
+		}
 
-		// Add random delay between requests (except for last one)
-		if i < len(streamers)-1 {
+		// Add random delay between requests
+		if len(queried) > 0 {
 			delay := time.Duration(minDelay+rand.Intn(maxDelay-minDelay+1)) * time.Second
-			time.Sleep(delay)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				s.cancelProbe(platform)
+				return queried
+			}
 		}
+
+		// Scan the streamer
+		s.scanStreamer(ctx, sc, c, requestTimeout)
+		queried = append(queried, sc.ID)
 	}
+	return queried
 }
 
-func (s *Service) scanStreamer(sc model.StreamerConfig, c crawler.Crawler) {
-	result, err := c.GetLiveStatus(sc.RoomID)
+func (s *Service) scanStreamer(ctx context.Context, sc model.StreamerConfig, c crawler.Crawler, timeout time.Duration) {
+	logger := logging.FromContext(ctx).With(streamerAttrs(sc)...)
+	reqCtx, cancel := context.WithTimeout(logging.NewContext(ctx, logger), timeout)
+	queried := time.Now()
+	result, err := c.GetLiveStatus(reqCtx, sc.RoomID)
+	cancel()
+	duration := time.Since(queried)
 	now := time.Now().Format("2006-01-02 15:04:05")
 
+	// A cancelled scan says nothing about the streamer, leave its state alone
+	if err != nil && ctx.Err() != nil {
+		s.cancelProbe(sc.Platform)
+		return
+	}
+
+	errKind := ""
+	if err != nil {
+		errKind = crawler.Kind(err)
+	}
+
+	platform := string(sc.Platform)
+	s.metrics.queries.Inc(platform)
+	s.metrics.latency.Observe(duration.Seconds(), platform)
+	if err != nil {
+		s.metrics.failures.Inc(platform, errKind)
+	} else {
+		s.metrics.successes.Inc(platform)
+	}
+
 	if err != nil {
 		s.mu.Lock()
+		s.recordQuery(logger, sc.Platform, errKind)
+		// Mark as failed
+		streamer, ok := s.streamers[sc.ID]
+		if !ok || !sameRoom(streamer, sc) {
+			// Removed or changed by a config reload during the scan
+			s.mu.Unlock()
+			return
+		}
 		s.errorCounts[sc.ID]++
+		s.platformFailures[sc.Platform]++
 		count := s.errorCounts[sc.ID]
-		// Mark as failed
-		streamer := s.streamers[sc.ID]
 		streamer.LastQueryTime = now
-		streamer.LastQueryFailed = true
+		streamer.LastError = errKind
+		snapshot := *streamer
 		s.mu.Unlock()
 
+		// Only the transition into the failed state is interesting to clients
+		if count == 1 {
+			s.events.Publish(events.Event{
+				Type:     events.ScanFailed,
+				Streamer: snapshot,
+				Error:    err.Error(),
+			})
+		}
+
 		// Update database with failed status
-		s.db.UpdateStreamerStatus(sc.ID, false, "", 0, true)
+		s.db.UpdateStreamerStatus(sc.ID, false, "", 0, errKind)
 
-		// Only log error on first occurrence or every 10th consecutive error
+		// Only warn on the first and every 10th consecutive error, the
+		// others are logged at debug level
+		level := slog.LevelDebug
 		if count == 1 || count%10 == 0 {
-			log.Printf("Error scanning %s (%s): %v (count: %d)", sc.Name, sc.Platform, err, count)
+			level = slog.LevelWarn
 		}
+		logger.Log(ctx, level, "Query failed", "duration", duration, "error_count", count, "kind", errKind, "error", err)
 		return
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	s.recordQuery(logger, sc.Platform, "")
+	streamer, ok := s.streamers[sc.ID]
+	if !ok || !sameRoom(streamer, sc) {
+		// Removed or changed by a config reload during the scan
+		return
+	}
+
+	logger.Debug("Query succeeded",
+		"duration", duration,
+		"is_live", result.IsLive,
+		"viewers", result.ViewerCount,
+		"title", result.Title)
+
 	// Reset error count on success
 	s.errorCounts[sc.ID] = 0
+	s.platformFailures[sc.Platform] = 0
+	s.lastSuccess[sc.ID] = time.Now()
 
-	streamer := s.streamers[sc.ID]
 	wasLive := streamer.IsLive
+	prevTitle := streamer.Title
+
+	// Crawlers occasionally misreport a live room as offline, so an open
+	// session only ends after enough consecutive offline results
+	pendingOffline := false
+	if wasLive && !result.IsLive {
+		state := s.offline[sc.ID]
+		if state == nil {
+			state = &offlineState{since: time.Now()}
+			s.offline[sc.ID] = state
+		}
+		state.count++
+		pendingOffline = state.count < s.settings.OfflineConfirmations
+	} else if result.IsLive {
+		delete(s.offline, sc.ID)
+	}
+	isLive := result.IsLive || pendingOffline
 
-	// Update streamer info
-	streamer.IsLive = result.IsLive
-	streamer.Title = result.Title
-	streamer.ViewerCount = result.ViewerCount
+	// Update streamer info, keeping the last live details while unconfirmed
+	streamer.IsLive = isLive
+	if !pendingOffline {
+		streamer.Title = result.Title
+		streamer.ViewerCount = result.ViewerCount
+	}
 	streamer.LastQueryTime = now
-	streamer.LastQueryFailed = false
+	streamer.LastError = ""
 
 	// Keep RoomURL from config, only update if crawler provides one and config doesn't have it
 	if streamer.RoomURL == "" && result.RoomURL != "" {
@@ -260,34 +580,147 @@ func (s *Service) scanStreamer(sc model.StreamerConfig, c crawler.Crawler) {
 	}
 
 	// Update database with query time and status
-	if err := s.db.UpdateStreamerStatus(sc.ID, result.IsLive, result.Title, result.ViewerCount, false); err != nil {
-		log.Printf("Error updating streamer status for %s: %v", sc.Name, err)
+	if err := s.db.UpdateStreamerStatus(sc.ID, isLive, streamer.Title, streamer.ViewerCount, ""); err != nil {
+		logger.Error("Error updating streamer status", "error", err)
 	}
 
 	// Handle session tracking
-	if result.IsLive && !wasLive {
+	if isLive && !wasLive {
 		// Started streaming
-		sessionID, err := s.db.StartSession(sc.ID, sc.Platform, sc.RoomID, result.Title)
-		if err != nil {
-			log.Printf("Error starting session for %s: %v", sc.Name, err)
-		} else {
-			s.sessions[sc.ID] = sessionID
-			log.Printf("%s started streaming: %s", sc.Name, result.Title)
+		s.startSession(logger, sc, streamer)
+	} else if !isLive && wasLive {
+		// Stopped streaming, as of the first offline observation
+		endTime := time.Now()
+		if state := s.offline[sc.ID]; state != nil {
+			endTime = state.since
+			delete(s.offline, sc.ID)
 		}
-	} else if !result.IsLive && wasLive {
-		// Stopped streaming
+		// The event only refers to a session that was actually ended
+		var endedID int64
 		if sessionID, ok := s.sessions[sc.ID]; ok {
-			if err := s.db.EndSession(sessionID); err != nil {
-				log.Printf("Error ending session for %s: %v", sc.Name, err)
+			if err := s.db.EndSession(sessionID, endTime, model.EndReasonOffline); err != nil {
+				logger.Error("Error ending session", "session_id", sessionID, "error", err)
 			} else {
 				delete(s.sessions, sc.ID)
-				log.Printf("%s stopped streaming", sc.Name)
+				s.ended[sc.ID] = endedSession{id: sessionID, confirmed: time.Now()}
+				endedID = sessionID
+				logger.Info("Stopped streaming", "name", sc.Name, "session_id", sessionID)
 			}
+		} else {
+			logger.Info("Stopped streaming", "name", sc.Name)
 		}
+		s.emit(notify.Event{Type: notify.EventLiveEnd, Streamer: *streamer, SessionID: endedID})
 		streamer.StartTime = ""
+	} else if pendingOffline {
+		logger.Info("Reported offline, waiting for confirmation",
+			"confirmations", s.offline[sc.ID].count,
+			"required", s.settings.OfflineConfirmations)
+	} else if result.IsLive && wasLive && result.Title != prevTitle && result.Title != "" {
+		sessionID, ok := s.sessions[sc.ID]
+		if ok {
+			if err := s.db.AddSessionTitle(sessionID, result.Title); err != nil {
+				logger.Error("Error recording title change", "session_id", sessionID, "error", err)
+			}
+		}
+		logger.Info("Changed title", "name", sc.Name, "title", result.Title, "prev_title", prevTitle)
+		s.emit(notify.Event{
+			Type:      notify.EventTitleChange,
+			Streamer:  *streamer,
+			SessionID: sessionID,
+			PrevTitle: prevTitle,
+		})
+	}
+
+	// Record the viewer count series of the open session
+	if result.IsLive {
+		if sessionID, ok := s.sessions[sc.ID]; ok {
+			if err := s.db.AddViewerSample(sessionID, result.ViewerCount); err != nil {
+				logger.Error("Error recording viewers", "session_id", sessionID, "error", err)
+			}
+		}
 	}
 }
 
+// startSession opens a session for a streamer that went live. If the
+// previous session ended within the merge grace window it is reopened
+// instead, so one broadcast interrupted by a misreported offline result
+// stays a single session and does not notify again. Must hold s.mu.
+func (s *Service) startSession(logger *slog.Logger, sc model.StreamerConfig, streamer *model.Streamer) {
+	// The new session changes when the streamer was last live
+	delete(s.habits, sc.ID)
+
+	grace := time.Duration(s.settings.SessionMergeGraceMinutes) * time.Minute
+	last, err := s.db.GetLastSession(sc.ID)
+	ended, hasEnded := s.ended[sc.ID]
+	delete(s.ended, sc.ID)
+	if err != nil {
+		logger.Error("Error getting last session", "error", err)
+	} else if last != nil && last.EndTime != nil && time.Since(mergeGraceStart(last, ended, hasEnded)) <= grace {
+		if err := s.db.ReopenSession(last.ID); err != nil {
+			logger.Error("Error reopening session", "session_id", last.ID, "error", err)
+		} else {
+			s.sessions[sc.ID] = last.ID
+			if streamer.StartTime == "" {
+				streamer.StartTime = last.StartTime.Format("2006-01-02 15:04:05")
+			}
+			logger.Info("Resumed streaming, merged into previous session", "name", sc.Name, "session_id", last.ID)
+			// Dashboards still need the state change, notifiers do not
+			s.events.Publish(events.Event{
+				Type:      events.LiveStart,
+				Streamer:  *streamer,
+				SessionID: last.ID,
+			})
+			return
+		}
+	}
+
+	// Without a session the event still goes out, just without session_id
+	sessionID, err := s.db.StartSession(sc.ID, sc.Platform, sc.RoomID, streamer.Title)
+	if err != nil {
+		logger.Error("Error starting session", "error", err)
+		sessionID = 0
+	} else {
+		s.sessions[sc.ID] = sessionID
+		logger.Info("Started streaming", "name", sc.Name, "session_id", sessionID, "title", streamer.Title)
+	}
+	s.emit(notify.Event{Type: notify.EventLiveStart, Streamer: *streamer, SessionID: sessionID})
+}
+
+// mergeGraceStart returns when the merge grace window after a session
+// began. Its recorded end is the first offline observation, but confirming
+// it took further scans, so the window starts at the confirmation when this
+// run saw it.
+func mergeGraceStart(last *model.LiveSession, ended endedSession, hasEnded bool) time.Time {
+	if hasEnded && ended.id == last.ID && ended.confirmed.After(*last.EndTime) {
+		return ended.confirmed
+	}
+	return *last.EndTime
+}
+
+// emit queues an event for the registered notifiers and event stream
+// subscribers. Both only enqueue, so this is safe to call while holding s.mu.
+func (s *Service) emit(ev notify.Event) {
+	ev.Time = time.Now()
+	s.dispatcher.Dispatch(ev)
+	s.events.Publish(events.Event{
+		Type:      events.Type(ev.Type),
+		Time:      ev.Time,
+		Streamer:  ev.Streamer,
+		SessionID: ev.SessionID,
+		PrevTitle: ev.PrevTitle,
+	})
+}
+
+// Events returns the hub that streams status changes to clients.
+func (s *Service) Events() *events.Hub {
+	return s.events
+}
+
+// Dispatcher returns the notification dispatcher fed by live transitions.
+func (s *Service) Dispatcher() *notify.Dispatcher {
+	return s.dispatcher
+}
+
 func (s *Service) GetStreamers() []*model.Streamer {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -306,6 +739,20 @@ func (s *Service) GetHistory(streamerID string, limit int) ([]model.LiveSession,
 	return s.db.GetHistory(streamerID, limit)
 }
 
+// GetSession returns a session with its title history, or nil if not found
+func (s *Service) GetSession(sessionID int64) (*model.LiveSession, error) {
+	return s.db.GetSession(sessionID)
+}
+
+func (s *Service) GetViewerSamples(sessionID int64) ([]model.ViewerSample, error) {
+	return s.db.GetViewerSamples(sessionID)
+}
+
 func (s *Service) GetStats(streamerID string) (*model.StreamerStats, error) {
 	return s.db.GetStats(streamerID)
 }
+
+// streamerAttrs are the log attributes identifying a streamer.
+func streamerAttrs(sc model.StreamerConfig) []any {
+	return []any{"streamer_id", sc.ID, "platform", sc.Platform, "room_id", sc.RoomID}
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"cxtv-alerts/internal/database"
+	"cxtv-alerts/internal/events"
+	"cxtv-alerts/internal/model"
+)
+
+// fakeCrawler reports a fixed live status for every room.
+type fakeCrawler struct {
+	mu   sync.Mutex
+	live bool
+}
+
+func (f *fakeCrawler) GetLiveStatus(ctx context.Context, roomID string) (*model.Streamer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &model.Streamer{RoomID: roomID, IsLive: f.live, Title: "测试直播"}, nil
+}
+
+func (f *fakeCrawler) Platform() model.Platform {
+	return model.PlatformBilibili
+}
+
+func (f *fakeCrawler) setLive(live bool) {
+	f.mu.Lock()
+	f.live = live
+	f.mu.Unlock()
+}
+
+// newTestService starts a service on a fresh database, tracking the given
+// streamers with default settings (there is no settings.json).
+func newTestService(t *testing.T, streamers string) *Service {
+	t.Helper()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "streamers.json")
+	if err := os.WriteFile(configPath, []byte(`{"streamers": `+streamers+`}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	db, err := database.New(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	s, err := New(db, Options{
+		ConfigPath:   configPath,
+		SettingsPath: filepath.Join(dir, "settings.json"),
+		AvatarDir:    filepath.Join(dir, "avatars"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.dispatcher.Close)
+	return s
+}
+
+// subscribe returns a function draining the live status events published
+// since the call, ignoring scan failures.
+func subscribe(t *testing.T, s *Service) func() []events.Event {
+	t.Helper()
+	_, ch, cancel, _ := s.events.Subscribe(0)
+	t.Cleanup(cancel)
+	return func() []events.Event {
+		var evs []events.Event
+		for {
+			select {
+			case ev := <-ch:
+				if ev.Type != events.ScanFailed {
+					evs = append(evs, ev)
+				}
+			default:
+				return evs
+			}
+		}
+	}
+}
+
+func TestEventsWithoutSession(t *testing.T) {
+	s := newTestService(t, testStreamers)
+	sc := s.config.Streamers[0]
+	c := &fakeCrawler{live: true}
+	drain := subscribe(t, s)
+
+	s.scanStreamer(context.Background(), sc, c, time.Second)
+	if evs := drain(); len(evs) != 1 || evs[0].SessionID == 0 {
+		t.Fatalf("events %+v, want live_start with a session", evs)
+	}
+
+	// Once sessions cannot be stored, transitions are still announced, but
+	// without a session that was never ended or started
+	s.db.Close()
+	c.setLive(false)
+	for i := 0; i < s.settings.OfflineConfirmations; i++ {
+		s.scanStreamer(context.Background(), sc, c, time.Second)
+	}
+	c.setLive(true)
+	s.scanStreamer(context.Background(), sc, c, time.Second)
+
+	evs := drain()
+	if len(evs) != 2 || evs[0].Type != events.LiveEnd || evs[1].Type != events.LiveStart {
+		t.Fatalf("events %+v, want live_end and live_start", evs)
+	}
+	for _, ev := range evs {
+		if ev.SessionID != 0 {
+			t.Errorf("%s event refers to session %d", ev.Type, ev.SessionID)
+		}
+	}
+}
+
+const testStreamers = `[{"id": "bilibili_1", "name": "测试主播", "platform": "bilibili", "room_id": "1"}]`
+
+// TestOfflineFlap covers a live room misreported as offline fewer times than
+// offline_confirmations: the session stays open and nobody is told.
+func TestOfflineFlap(t *testing.T) {
+	const id = "bilibili_1"
+	s := newTestService(t, testStreamers)
+	sc := s.config.Streamers[0]
+	c := &fakeCrawler{live: true}
+	drain := subscribe(t, s)
+
+	s.scanStreamer(context.Background(), sc, c, time.Second)
+	sessionID := s.sessions[id]
+	drain()
+
+	c.setLive(false)
+	for i := 0; i < s.settings.OfflineConfirmations-1; i++ {
+		s.scanStreamer(context.Background(), sc, c, time.Second)
+	}
+	c.setLive(true)
+	s.scanStreamer(context.Background(), sc, c, time.Second)
+
+	if evs := drain(); len(evs) != 0 {
+		t.Errorf("events %+v during a flap", evs)
+	}
+	if s.sessions[id] != sessionID {
+		t.Errorf("session %d after the flap, want %d", s.sessions[id], sessionID)
+	}
+	if session, err := s.db.GetActiveSession(id); err != nil || session == nil || session.ID != sessionID {
+		t.Errorf("active session %+v (%v), want %d", session, err, sessionID)
+	}
+	if s.offline[id] != nil {
+		t.Error("offline confirmations not reset by the live result")
+	}
+}
+
+func TestSessionMergeGrace(t *testing.T) {
+	tests := []struct {
+		name      string
+		confirmed time.Duration // how long before going live again offline was confirmed
+		merged    bool
+	}{
+		{"back soon after confirmation", -time.Minute, true},
+		{"back after the grace window", time.Minute, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const id = "bilibili_1"
+			s := newTestService(t, testStreamers)
+			sc := s.config.Streamers[0]
+			settings := s.settings
+			grace := time.Duration(settings.SessionMergeGraceMinutes) * time.Minute
+			// Confirming offline takes further scans after the first offline one
+			confirmDelay := time.Duration(settings.OfflineConfirmations-1) * time.Duration(settings.ScanIntervalMinutes) * time.Minute
+			c := &fakeCrawler{live: true}
+			drain := subscribe(t, s)
+
+			s.scanStreamer(context.Background(), sc, c, time.Second)
+			sessionID, ok := s.sessions[id]
+			if !ok {
+				t.Fatal("no session after going live")
+			}
+
+			c.setLive(false)
+			for i := 0; i < settings.OfflineConfirmations; i++ {
+				s.scanStreamer(context.Background(), sc, c, time.Second)
+			}
+			if _, ok := s.sessions[id]; ok {
+				t.Fatalf("session still open after %d offline scans", settings.OfflineConfirmations)
+			}
+
+			// Move the session end and its confirmation into the past
+			confirmed := time.Now().Add(-grace - tt.confirmed)
+			if err := s.db.EndSession(sessionID, confirmed.Add(-confirmDelay), model.EndReasonOffline); err != nil {
+				t.Fatal(err)
+			}
+			s.ended[id] = endedSession{id: sessionID, confirmed: confirmed}
+			drain()
+
+			c.setLive(true)
+			s.scanStreamer(context.Background(), sc, c, time.Second)
+			if merged := s.sessions[id] == sessionID; merged != tt.merged {
+				t.Errorf("merged = %v, want %v (session %d, previous %d)", merged, tt.merged, s.sessions[id], sessionID)
+			}
+			// Dashboards see the streamer going live either way
+			if evs := drain(); len(evs) != 1 || evs[0].Type != events.LiveStart {
+				t.Errorf("events %+v, want one live_start", evs)
+			}
+		})
+	}
+}
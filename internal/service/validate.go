@@ -0,0 +1,197 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"cxtv-alerts/internal/crawler"
+	"cxtv-alerts/internal/logging"
+	"cxtv-alerts/internal/model"
+	"cxtv-alerts/internal/notify"
+)
+
+// Problem is one invalid value in a config file. Path is the JSON path of
+// the value, e.g. streamers[3].room_id.
+type Problem struct {
+	File    string `json:"file,omitempty"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	var b strings.Builder
+	if p.File != "" {
+		b.WriteString(p.File + ": ")
+	}
+	if p.Path != "" {
+		b.WriteString(p.Path + ": ")
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+// Problems is the result of a validation. As an error it lists every
+// problem.
+type Problems []Problem
+
+func (ps Problems) Error() string {
+	lines := make([]string, len(ps))
+	for i, p := range ps {
+		lines[i] = p.String()
+	}
+	return strings.Join(lines, "; ")
+}
+
+// Err returns ps as an error, or nil if there are no problems.
+func (ps Problems) Err() error {
+	if len(ps) == 0 {
+		return nil
+	}
+	return ps
+}
+
+// Validate checks a streamer list and settings for values that would break
+// scanning or notifications. configFile and settingsFile only label the
+// problems.
+func Validate(config *model.Config, settings *model.Settings, configFile, settingsFile string) Problems {
+	var ps Problems
+	add := func(file, path, format string, args ...any) {
+		ps = append(ps, Problem{File: file, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	ids := make(map[string]int)
+	for i, sc := range config.Streamers {
+		path := fmt.Sprintf("streamers[%d]", i)
+		if sc.ID == "" {
+			add(configFile, path+".id", "must not be empty")
+		} else if first, ok := ids[sc.ID]; ok {
+			add(configFile, path+".id", "duplicate id %q, first used by streamers[%d]", sc.ID, first)
+		} else {
+			ids[sc.ID] = i
+		}
+		if !crawler.Supported(sc.Platform) {
+			add(configFile, path+".platform", "unknown platform %q", sc.Platform)
+		}
+		if strings.TrimSpace(sc.RoomID) == "" {
+			add(configFile, path+".room_id", "must not be empty")
+		} else if sc.RoomID != strings.TrimSpace(sc.RoomID) {
+			add(configFile, path+".room_id", "must not contain surrounding whitespace")
+		}
+		if sc.LiveURL != "" && !isHTTPURL(sc.LiveURL) {
+			add(configFile, path+".live_url", "must be an http(s) URL")
+		}
+		if sc.Avatar != "" && !isHTTPURL(sc.Avatar) {
+			add(configFile, path+".avatar", "must be an http(s) URL")
+		}
+	}
+
+	if settings == nil {
+		return ps
+	}
+	if settings.ScanIntervalMinutes <= 0 {
+		add(settingsFile, "scan_interval_minutes", "must be positive")
+	}
+	if settings.PlatformDelayMinSeconds < 0 {
+		add(settingsFile, "platform_delay_min_seconds", "must not be negative")
+	}
+	if settings.PlatformDelayMaxSeconds < settings.PlatformDelayMinSeconds {
+		add(settingsFile, "platform_delay_max_seconds", "must not be less than platform_delay_min_seconds (%d)", settings.PlatformDelayMinSeconds)
+	}
+	if settings.BreakerBackoffMinutes > 0 && settings.BreakerMaxBackoffMinutes > 0 &&
+		settings.BreakerMaxBackoffMinutes < settings.BreakerBackoffMinutes {
+		add(settingsFile, "breaker_max_backoff_minutes", "must not be less than breaker_backoff_minutes (%d)", settings.BreakerBackoffMinutes)
+	}
+	if settings.PublicURL != "" && !isHTTPURL(settings.PublicURL) {
+		add(settingsFile, "public_url", "must be an http(s) URL")
+	}
+	if settings.LogLevel != "" {
+		if _, err := logging.ParseLevel(settings.LogLevel); err != nil {
+			add(settingsFile, "log_level", "must be debug, info, warn or error")
+		}
+	}
+
+	if tg := settings.Telegram; tg != nil {
+		if tg.BotToken == "" {
+			add(settingsFile, "telegram.bot_token", "must not be empty")
+		}
+		if tg.APIBase != "" && !isHTTPURL(tg.APIBase) {
+			add(settingsFile, "telegram.api_base", "must be an http(s) URL")
+		}
+		for j, name := range tg.Events {
+			if _, err := notify.EventTypes([]string{name}); err != nil {
+				add(settingsFile, fmt.Sprintf("telegram.events[%d]", j), "%v", err)
+			}
+		}
+	}
+
+	for i, wh := range settings.Webhooks {
+		path := fmt.Sprintf("webhooks[%d]", i)
+		for j, id := range wh.Streamers {
+			if _, ok := ids[id]; !ok {
+				add(settingsFile, fmt.Sprintf("%s.streamers[%d]", path, j), "unknown streamer %q", id)
+			}
+		}
+		for j, p := range wh.Platforms {
+			if !crawler.Supported(p) {
+				add(settingsFile, fmt.Sprintf("%s.platforms[%d]", path, j), "unknown platform %q", p)
+			}
+		}
+		for j, name := range wh.Events {
+			if _, err := notify.EventTypes([]string{name}); err != nil {
+				add(settingsFile, fmt.Sprintf("%s.events[%d]", path, j), "%v", err)
+			}
+		}
+		if wh.URL != "" && !isHTTPURL(wh.URL) {
+			add(settingsFile, path+".url", "must be an http(s) URL")
+		}
+		// Remaining type specific checks (required fields, template syntax)
+		// are done by building the notifier; events were checked above
+		built := wh
+		built.Events = nil
+		if _, err := notify.NewWebhook(built, settings.PublicURL); err != nil {
+			add(settingsFile, path, "%v", err)
+		}
+	}
+
+	return ps
+}
+
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// ValidateFiles loads and validates the config files, reporting unreadable
+// or malformed files as problems too.
+func ValidateFiles(configPath, settingsPath string) Problems {
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return Problems{{File: configPath, Message: describeLoadError(err)}}
+	}
+	settings, err := loadSettings(settingsPath)
+	if err != nil {
+		return Problems{{File: settingsPath, Message: describeLoadError(err)}}
+	}
+	return Validate(config, settings, configPath, settingsPath)
+}
+
+// describeLoadError adds the position to JSON errors that only carry a byte
+// offset.
+func describeLoadError(err error) string {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return fmt.Sprintf("%v (at byte %d)", err, syntaxErr.Offset)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return fmt.Sprintf("%s: cannot use %s as %s", typeErr.Field, typeErr.Value, typeErr.Type)
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return "file does not exist"
+	}
+	return err.Error()
+}
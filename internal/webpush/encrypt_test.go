@@ -0,0 +1,32 @@
+package webpush
+
+import (
+	"crypto/ecdh"
+	"testing"
+
+	"cxtv-alerts/internal/model"
+)
+
+// TestEncryptRFC8291 checks the example from RFC 8291 Appendix A.
+func TestEncryptRFC8291(t *testing.T) {
+	asPrivateBytes, _ := b64.DecodeString("yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw")
+	asPrivate, err := ecdh.P256().NewPrivateKey(asPrivateBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	salt, _ := b64.DecodeString("DGv6ra1nlYgDCS1FRnbzlw")
+
+	var sub model.PushSubscription
+	sub.Keys.P256dh = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+	sub.Keys.Auth = "BTBZMqHH6r4Tts7J_aSIgg"
+
+	got, err := encrypt(sub, []byte("When I grow up, I want to be a watermelon"), asPrivate, salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN"
+	if b64.EncodeToString(got) != want {
+		t.Errorf("encrypt =\n%s\nwant\n%s", b64.EncodeToString(got), want)
+	}
+}
@@ -0,0 +1,122 @@
+// Package webpush implements sending Web Push messages with VAPID
+// authentication (RFC 8292) and aes128gcm payload encryption (RFC 8291).
+package webpush
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var b64 = base64.RawURLEncoding
+
+// VAPID holds the application server key pair used to authenticate push
+// requests.
+type VAPID struct {
+	key       *ecdsa.PrivateKey
+	publicKey []byte // uncompressed P-256 point
+	subject   string
+}
+
+// LoadOrGenerateVAPID reads the PEM encoded private key at path, creating a
+// new key pair there if the file does not exist. The key must stay stable:
+// browsers bind their subscriptions to it. subject is the contact URL or
+// mailto: address sent to push services.
+func LoadOrGenerateVAPID(path, subject string) (*VAPID, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return generateVAPID(path, subject)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok || key.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("%s: not a P-256 ECDSA key", path)
+	}
+	return newVAPID(key, subject)
+}
+
+func generateVAPID(path, subject string) (*VAPID, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, pemData, 0600); err != nil {
+		return nil, err
+	}
+	return newVAPID(key, subject)
+}
+
+func newVAPID(key *ecdsa.PrivateKey, subject string) (*VAPID, error) {
+	pub, err := key.PublicKey.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	return &VAPID{key: key, publicKey: pub.Bytes(), subject: subject}, nil
+}
+
+// PublicKey returns the application server key in the base64url form
+// expected by PushManager.subscribe.
+func (v *VAPID) PublicKey() string {
+	return b64.EncodeToString(v.publicKey)
+}
+
+// authorization builds the "vapid" Authorization header for an endpoint.
+func (v *VAPID) authorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	header := b64.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`))
+	claims, err := json.Marshal(map[string]any{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+		"sub": v.subject,
+	})
+	if err != nil {
+		return "", err
+	}
+	signingInput := header + "." + b64.EncodeToString(claims)
+
+	hash := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, v.key, hash[:])
+	if err != nil {
+		return "", err
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	token := signingInput + "." + b64.EncodeToString(sig)
+	return fmt.Sprintf("vapid t=%s, k=%s", token, v.PublicKey()), nil
+}
@@ -1,66 +1,121 @@
 package main
 
 import (
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"cxtv-alerts/internal/database"
-	"cxtv-alerts/internal/handler"
 	"cxtv-alerts/internal/service"
-
-	"github.com/gin-gonic/gin"
 )
 
-const Version = "1.0.1" // Increment this when updating JS/CSS files
+type command struct {
+	name string
+	args string
+	help string
+	run  func(args []string) int
+}
 
-func main() {
-	// Ensure data directory exists
-	if err := os.MkdirAll("data", 0755); err != nil {
-		log.Fatalf("Failed to create data directory: %v", err)
-	}
+var commands []command
 
-	// Initialize database
-	db, err := database.New("data/data.db")
-	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+func init() {
+	commands = []command{
+		{"serve", "[-listen addr] [-base-path prefix]", "run the scanner and web server (default)", runServe},
+		{"scan-once", "", "scan every streamer once and print a summary", runScanOnce},
+		{"check", "[-log-level debug] <platform> <room_id>", "query one room and print the parsed result", runCheck},
+		{"resolve", "<room-url>", "print the streamers.json entry for a room link", runResolve},
+		{"validate", "[streamers.json [settings.json]]", "check the config files", runValidate},
+		{"export", "[-format json|csv] [-o file] [-streamer id] [-since YYYY-MM-DD]", "export session history", runExport},
+		{"migrate", "[-vacuum]", "apply database migrations and check integrity", runMigrate},
 	}
-	defer db.Close()
+}
 
-	// Initialize service
-	svc, err := service.New(db, "config/streamers.json", "config/settings.json")
-	if err != nil {
-		log.Fatalf("Failed to initialize service: %v", err)
+func main() {
+	// Without a command behave as before and serve
+	if len(os.Args) < 2 || strings.HasPrefix(os.Args[1], "-") && os.Args[1] != "-h" && os.Args[1] != "--help" {
+		os.Exit(runServe(os.Args[1:]))
 	}
 
-	// Start background scanner
-	svc.StartScanner()
+	name := os.Args[1]
+	for _, cmd := range commands {
+		if cmd.name == name {
+			os.Exit(cmd.run(os.Args[2:]))
+		}
+	}
+	if name != "help" && name != "-h" && name != "--help" {
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", name)
+		usage()
+		os.Exit(2)
+	}
+	usage()
+}
 
-	// Start avatar updater (downloads avatars daily)
-	svc.StartAvatarUpdater()
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cxtv-alerts <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.help)
+		if cmd.args != "" {
+			fmt.Fprintf(os.Stderr, "  %-10s   %s %s\n", "", cmd.name, cmd.args)
+		}
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "All commands also accept -data-dir, -db, -streamers, -settings, -avatar-dir,")
+	fmt.Fprintln(os.Stderr, "-log-format and -log-level, see <command> -h.")
+}
 
-	// Setup Gin
-	gin.SetMode(gin.ReleaseMode)
-	r := gin.Default()
+// flagError returns the exit code for a failed fs.Parse, which has already
+// printed the problem.
+func flagError(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	return 2
+}
 
-	// Load HTML templates
-	r.LoadHTMLGlob("web/*.html")
+// usageError reports wrong arguments and returns the exit code for them.
+func usageError(msg string) int {
+	fmt.Fprintf(os.Stderr, "%s\n\n", msg)
+	usage()
+	return 2
+}
 
-	// Serve static files
-	r.Static("/static", "./web")
+// openService opens the database and builds the service, with its
+// notifiers when notify is set.
+func openService(o *options, notify bool) (*database.DB, *service.Service, error) {
+	// Ensure data directory exists
+	for _, dir := range []string{o.dataDir, filepath.Dir(o.database())} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, nil, fmt.Errorf("failed to create data directory: %w", err)
+		}
+	}
 
-	// Serve index with version for cache busting
-	r.GET("/", func(c *gin.Context) {
-		c.HTML(200, "index.html", gin.H{
-			"Version": Version,
-		})
-	})
+	// Initialize database
+	db, err := database.New(o.database())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
 
-	// Register API routes
-	h := handler.New(svc)
-	h.RegisterRoutes(r)
+	// Initialize service
+	opts := o.service()
+	opts.NoNotify = !notify
+	svc, err := service.New(db, opts)
+	if err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("failed to initialize service: %w", err)
+	}
+	if !notify {
+		return db, svc, nil
+	}
 
-	log.Println("Server starting on http://localhost:8080")
-	if err := r.Run(":8080"); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	// Enable browser push notifications (keys are generated on first start)
+	if err := svc.EnableWebPush(o.vapidKeyPath()); err != nil {
+		slog.Warn("Web push disabled", "error", err)
 	}
+	return db, svc, nil
 }
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cxtv-alerts/internal/logging"
+	"cxtv-alerts/internal/service"
+)
+
+// options holds the locations every command may need. Each one can be set
+// with a flag or the CXTV_* environment variable named in its help text,
+// the flag taking precedence.
+type options struct {
+	listen       string
+	dataDir      string
+	dbPath       string
+	configPath   string
+	settingsPath string
+	avatarDir    string
+	basePath     string
+	webDir       string
+	logFormat    string
+	logLevel     string
+}
+
+// newFlagSet returns a flag set for a command with the path flags
+// registered. The returned options are filled in by fs.Parse.
+func newFlagSet(name string) (*flag.FlagSet, *options) {
+	o := &options{}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	stringFlag(fs, &o.dataDir, "data-dir", "CXTV_DATA_DIR", "data", "`dir` for the database and push keys")
+	stringFlag(fs, &o.dbPath, "db", "CXTV_DB", "", "database `file` (default <data-dir>/data.db)")
+	stringFlag(fs, &o.configPath, "streamers", "CXTV_STREAMERS", "config/streamers.json", "streamer list `file`")
+	stringFlag(fs, &o.settingsPath, "settings", "CXTV_SETTINGS", "config/settings.json", "settings `file`")
+	stringFlag(fs, &o.avatarDir, "avatar-dir", "CXTV_AVATAR_DIR", "web/avatars", "`dir` for downloaded avatars")
+	stringFlag(fs, &o.logFormat, "log-format", "CXTV_LOG_FORMAT", "text", "log `format`: text or json")
+	stringFlag(fs, &o.logLevel, "log-level", "CXTV_LOG_LEVEL", "info", "log `level`: debug, info, warn or error; log_level in settings.json overrides it")
+	return fs, o
+}
+
+// parse parses the command line and sets up logging as it asks.
+func (o *options) parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	level, err := logging.ParseLevel(o.logLevel)
+	if err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		return err
+	}
+	logging.Level.Set(level)
+	if err := logging.Setup(os.Stderr, o.logFormat); err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		return err
+	}
+	return nil
+}
+
+// addServerFlags registers the flags only the web server uses.
+func (o *options) addServerFlags(fs *flag.FlagSet) {
+	stringFlag(fs, &o.listen, "listen", "CXTV_LISTEN", ":8080", "HTTP listen `address`")
+	stringFlag(fs, &o.basePath, "base-path", "CXTV_BASE_PATH", "", "URL `prefix` to serve under, e.g. /cxtv behind a reverse proxy")
+	stringFlag(fs, &o.webDir, "web-dir", "CXTV_WEB_DIR", "", "serve web files from `dir` instead of the built-in copy, for development")
+}
+
+func stringFlag(fs *flag.FlagSet, p *string, name, env, def, usage string) {
+	if v := os.Getenv(env); v != "" {
+		def = v
+	}
+	fs.StringVar(p, name, def, usage+" ($"+env+")")
+}
+
+// database returns the database path, which defaults to a file in the data
+// directory.
+func (o *options) database() string {
+	if o.dbPath != "" {
+		return o.dbPath
+	}
+	return filepath.Join(o.dataDir, "data.db")
+}
+
+func (o *options) vapidKeyPath() string {
+	return filepath.Join(o.dataDir, "vapid_private.pem")
+}
+
+func (o *options) service() service.Options {
+	return service.Options{
+		ConfigPath:   o.configPath,
+		SettingsPath: o.settingsPath,
+		AvatarDir:    o.avatarDir,
+	}
+}
+
+// normalizeBasePath turns "cxtv", "/cxtv/" and "/cxtv" into "/cxtv", and "/"
+// into "", so routes can be built as basePath + "/api".
+func normalizeBasePath(p string) (string, error) {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return "", nil
+	}
+	if strings.ContainsAny(p, "?#*: ") {
+		return "", fmt.Errorf("invalid base path: %q", p)
+	}
+	return "/" + p, nil
+}
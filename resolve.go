@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"cxtv-alerts/internal/service"
+)
+
+// runResolve prints the streamers.json entry for a pasted room link.
+func runResolve(args []string) int {
+	fs, o := newFlagSet("resolve")
+	if err := o.parse(fs, args); err != nil {
+		return flagError(err)
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: cxtv-alerts resolve <room-url>")
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	sc, err := service.ResolveURL(ctx, o.configPath, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "resolve: %v\n", err)
+		return 1
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	enc.Encode(sc)
+	return 0
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"cxtv-alerts/internal/crawler"
+	"cxtv-alerts/internal/model"
+)
+
+// runScanOnce scans every streamer once and prints the result. Sessions
+// are recorded as the server would, notifications are only sent with
+// -notify. Useful from cron or to debug a crawler without starting the web
+// server.
+func runScanOnce(args []string) int {
+	fs, o := newFlagSet("scan-once")
+	notify := fs.Bool("notify", false, "send notifications for status changes, as the server would")
+	if err := o.parse(fs, args); err != nil {
+		return flagError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError("scan-once takes no arguments")
+	}
+
+	db, svc, err := openService(o, *notify)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scan-once: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	queried := svc.ScanOnce(ctx)
+	// Flush queued notifications and persist the scanned state
+	svc.Shutdown()
+	if ctx.Err() != nil {
+		fmt.Fprintln(os.Stderr, "scan-once: interrupted")
+		return 1
+	}
+
+	streamers := svc.GetStreamers()
+	sort.Slice(streamers, func(i, j int) bool {
+		if streamers[i].Platform != streamers[j].Platform {
+			return streamers[i].Platform < streamers[j].Platform
+		}
+		return streamers[i].ID < streamers[j].ID
+	})
+
+	failed := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tPLATFORM\tSTATUS\tVIEWERS\tTITLE")
+	for _, st := range streamers {
+		status := "offline"
+		switch {
+		case !queried[st.ID]:
+			// Its status is whatever the database last had
+			status = "skipped (platform paused)"
+		case st.LastError != "":
+			status = "failed (" + st.LastError + ")"
+			failed++
+		case st.IsLive:
+			status = "live"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", st.ID, st.Name, st.Platform, status, st.ViewerCount, st.Title)
+	}
+	w.Flush()
+
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d queries failed\n", failed, len(queried))
+		return 1
+	}
+	return 0
+}
+
+// runCheck queries a single room and prints what the crawler parsed from
+// it, without touching the database.
+func runCheck(args []string) int {
+	fs, o := newFlagSet("check")
+	if err := o.parse(fs, args); err != nil {
+		return flagError(err)
+	}
+	if fs.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "usage: cxtv-alerts check [-log-level debug] <platform> <room_id>")
+		return 2
+	}
+	platform, roomID := model.Platform(fs.Arg(0)), fs.Arg(1)
+
+	c, ok := crawler.All()[platform]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "check: unknown platform: %s\n", platform)
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	status, err := c.GetLiveStatus(ctx, roomID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "check: %v\n", err)
+		return 1
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	enc.Encode(status)
+	return 0
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"cxtv-alerts/internal/handler"
+	"cxtv-alerts/web"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// shutdownTimeout bounds how long in-flight requests may take to finish
+// after SIGINT/SIGTERM. docker-compose's stop_grace_period must exceed it.
+const shutdownTimeout = 10 * time.Second
+
+// runServe runs the scanner, notifiers and web server until SIGINT/SIGTERM.
+func runServe(args []string) int {
+	fs, o := newFlagSet("serve")
+	o.addServerFlags(fs)
+	if err := o.parse(fs, args); err != nil {
+		return flagError(err)
+	}
+	if fs.NArg() > 0 {
+		return usageError("serve takes no arguments")
+	}
+	basePath, err := normalizeBasePath(o.basePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "serve: %v\n", err)
+		return 2
+	}
+	assets, err := web.New(o.webDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "serve: web files: %v\n", err)
+		return 1
+	}
+	if o.webDir != "" {
+		slog.Info("Serving web files from disk", "dir", o.webDir)
+	}
+
+	db, svc, err := openService(o, true)
+	if err != nil {
+		slog.Error("Startup failed", "error", err)
+		return 1
+	}
+	defer db.Close()
+
+	// Stop background loops on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Start background scanner
+	svc.StartScanner(ctx)
+
+	// Start avatar updater (downloads avatars daily)
+	svc.StartAvatarUpdater(ctx)
+
+	// Start notifier background loops (Telegram bot commands)
+	svc.StartNotifiers(ctx)
+
+	// Reload config files when they change or on SIGHUP
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	svc.StartConfigWatcher(ctx, hup)
+
+	// Setup Gin
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(gin.Recovery(), handler.Logger())
+	r.Use(handler.Metrics(svc.Metrics()))
+
+	// Everything is served under the base path, which the reverse proxy
+	// passes through unchanged
+	site := r.Group(basePath)
+
+	// Serve static files, with avatars from their own directory
+	site.GET("/static/*filepath", staticHandler(assets, o.avatarDir))
+
+	// The service worker must be served from the root to control the whole site
+	site.GET("/sw.js", func(c *gin.Context) {
+		assets.ServeFile(c.Writer, c.Request, "sw.js")
+	})
+
+	// Serve index with content hashes for cache busting
+	site.GET("/", func(c *gin.Context) {
+		tmpl, err := assets.Template()
+		if err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.Header("Cache-Control", "no-cache")
+		c.Render(http.StatusOK, render.HTML{
+			Template: tmpl,
+			Name:     "index.html",
+			Data: gin.H{
+				"BasePath":  basePath,
+				"AppHash":   assets.Hash("app.js"),
+				"StyleHash": assets.Hash("style.css"),
+			},
+		})
+	})
+
+	// Register API routes
+	h := handler.New(svc)
+	h.RegisterRoutes(site)
+
+	srv := &http.Server{
+		Addr:    o.listen,
+		Handler: r,
+	}
+	// Ordinary requests are drained on shutdown, event streams would never
+	// finish on their own
+	srv.RegisterOnShutdown(svc.Events().Close)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("Server starting", "address", o.listen, "base_path", basePath+"/")
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	status := 0
+	select {
+	case <-ctx.Done():
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Failed to start server", "error", err)
+			status = 1
+		}
+	}
+	stop()
+	slog.Info("Shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("HTTP server shutdown", "error", err)
+	}
+
+	svc.Shutdown()
+	slog.Info("Shutdown complete")
+	return status
+}
+
+// staticHandler serves /static/ from the web assets, except
+// /static/avatars/ which maps to avatarDir since avatars are downloaded at
+// runtime.
+func staticHandler(assets *web.Assets, avatarDir string) gin.HandlerFunc {
+	avatarFS := gin.Dir(avatarDir, false)
+	return func(c *gin.Context) {
+		p := c.Param("filepath")
+		if name, ok := strings.CutPrefix(p, "/avatars/"); ok {
+			c.FileFromFS(name, avatarFS)
+			return
+		}
+		assets.ServeFile(c.Writer, c.Request, p)
+	}
+}
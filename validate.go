@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"cxtv-alerts/internal/service"
+)
+
+// runValidate checks the config files and lists every problem found, for
+// use in CI before deploying config changes.
+func runValidate(args []string) int {
+	fs, o := newFlagSet("validate")
+	if err := o.parse(fs, args); err != nil {
+		return flagError(err)
+	}
+	args = fs.Args()
+	if len(args) > 2 {
+		fmt.Fprintln(os.Stderr, "usage: cxtv-alerts validate [streamers.json [settings.json]]")
+		return 2
+	}
+	configFile, settingsFile := o.configPath, o.settingsPath
+	if len(args) > 0 {
+		configFile = args[0]
+	}
+	if len(args) > 1 {
+		settingsFile = args[1]
+	}
+
+	problems := service.ValidateFiles(configFile, settingsFile)
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "%d problems found\n", len(problems))
+		return 1
+	}
+	fmt.Println("OK")
+	return 0
+}
@@ -7,11 +7,31 @@ const platformNames = {
     weibo: '微博'
 };
 
+// Labels for the kinds of query errors reported in last_error
+const errorLabels = {
+    rate_limited: '被限流',
+    not_found: '房间不存在',
+    blocked: '被风控',
+    rejected: '请求被拒',
+    parse: '解析失败',
+    transport: '网络错误'
+};
+
+function errorLabel(kind) {
+    return errorLabels[kind] || '失败';
+}
+
+// URL prefix when served behind a reverse proxy under a sub path, e.g. /cxtv
+const basePath = document.documentElement.dataset.basePath || '';
+
 let streamers = [];
 
+const pushSupported = 'serviceWorker' in navigator && 'PushManager' in window && 'Notification' in window;
+let pushStreamers = new Set(JSON.parse(localStorage.getItem('pushStreamers') || '[]'));
+
 async function fetchStreamers() {
     try {
-        const response = await fetch('/api/streamers');
+        const response = await fetch(basePath + '/api/streamers');
         const result = await response.json();
         if (result.code === 0) {
             streamers = result.data;
@@ -32,7 +52,7 @@ function renderStreamers() {
     }
 
     grid.innerHTML = streamers.map(s => {
-        const avatarSrc = s.avatar_local || s.avatar;
+        const avatarSrc = s.avatar_local ? basePath + s.avatar_local : s.avatar;
         return `
         <div class="streamer-card ${s.is_live ? 'live' : ''}" data-id="${s.id}">
             <div class="card-header">
@@ -61,10 +81,11 @@ function renderStreamers() {
                     </div>
                 ` : ''}
                 <div class="card-footer">
-                    <span class="last-query ${s.last_query_failed ? 'query-failed' : ''}" title="最后查询时间${s.last_query_failed ? ' (查询失败)' : ''}">
-                        ${s.last_query_failed ? '⚠️' : '🕐'} ${s.last_query_time ? formatQueryTime(s.last_query_time) : '未查询'}${s.last_query_failed ? ' 失败' : ''}
+                    <span class="last-query ${s.last_error ? 'query-failed' : ''}" title="最后查询时间${s.last_error ? ' (查询失败: ' + errorLabel(s.last_error) + ')' : ''}">
+                        ${s.last_error ? '⚠️' : '🕐'} ${s.last_query_time ? formatQueryTime(s.last_query_time) : '未查询'}${s.last_error ? ' ' + errorLabel(s.last_error) : ''}
                     </span>
                     <div class="card-actions">
+                        ${pushSupported ? `<button class="btn-push ${pushStreamers.has(s.id) ? 'active' : ''}" title="${pushStreamers.has(s.id) ? '取消开播提醒' : '开播提醒'}" onclick="event.stopPropagation(); togglePush('${s.id}')">${pushStreamers.has(s.id) ? '🔔' : '🔕'}</button>` : ''}
                         <button class="btn-stats" onclick="event.stopPropagation(); showStats('${s.id}', '${escapeHtml(s.name)}')">统计</button>
                         ${s.room_url ? `<a class="btn-open" href="${s.room_url}" target="_blank" onclick="event.stopPropagation()">打开直播间</a>` : ''}
                     </div>
@@ -114,8 +135,8 @@ async function showStats(id, name) {
 
     try {
         const [statsRes, historyRes] = await Promise.all([
-            fetch(`/api/stats/${id}`),
-            fetch(`/api/history/${id}?limit=10`)
+            fetch(`${basePath}/api/stats/${id}`),
+            fetch(`${basePath}/api/history/${id}?limit=10`)
         ]);
 
         const stats = await statsRes.json();
@@ -148,6 +169,7 @@ async function showStats(id, name) {
                 </div>
             </div>
             ${s.last_live_time ? `<p style="color: var(--text-secondary); margin-bottom: 1rem;">上次开播时间: ${parseUTCTimestamp(s.last_live_time).toLocaleString('zh-CN', { month: '2-digit', day: '2-digit', hour: '2-digit', minute: '2-digit' })}</p>` : ''}
+            <div class="viewer-section" id="viewerSection"></div>
             <div class="history-section">
                 <h3>近期开播记录</h3>
                 ${h.length > 0 ? `
@@ -155,9 +177,10 @@ async function showStats(id, name) {
                         ${h.map(item => `
                             <div class="history-item">
                                 <div class="title">${escapeHtml(item.title || '无标题')}</div>
+                                ${renderTitleHistory(item.titles)}
                                 <div class="meta">
-                                    <span>${formatDateTime(item.start_time)}</span>
-                                    <span>${item.duration ? formatDuration(item.duration) : '进行中'}</span>
+                                    <span>${formatDateTime(item.start_time)}${item.peak_viewers ? ` · 👁 峰值 ${formatNumber(item.peak_viewers)}` : ''}</span>
+                                    <span>${item.end_time == null ? '进行中' : formatDuration(item.duration)}${item.end_reason === 'unknown/downtime' ? ' <span class="end-unknown" title="服务停机期间结束，结束时间为最后一次检测到直播的时间">(结束时间未知)</span>' : ''}</span>
                                 </div>
                             </div>
                         `).join('')}
@@ -165,12 +188,127 @@ async function showStats(id, name) {
                 ` : '<p style="color: var(--text-secondary);">暂无开播记录</p>'}
             </div>
         `;
+
+        if (h.length > 0) {
+            loadViewerSparkline(h[0]);
+        }
     } catch (error) {
         console.error('Error fetching stats:', error);
         modalBody.innerHTML = '<div class="loading">加载失败</div>';
     }
 }
 
+// Titles a session went through, only shown when it was retitled mid-stream
+function renderTitleHistory(titles) {
+    if (!titles || titles.length < 2) return '';
+    return `
+        <details class="title-history">
+            <summary>改过 ${titles.length - 1} 次标题</summary>
+            <ol>
+                ${titles.map(t => `
+                    <li><span class="time">${formatDateTime(t.changed_at)}</span> ${escapeHtml(t.title || '无标题')}</li>
+                `).join('')}
+            </ol>
+        </details>
+    `;
+}
+
+async function loadViewerSparkline(session) {
+    try {
+        const response = await fetch(`${basePath}/api/sessions/${session.id}/viewers`);
+        const result = await response.json();
+        const section = document.getElementById('viewerSection');
+        if (result.code !== 0 || !section || result.data.length < 2) return;
+
+        section.innerHTML = `
+            <h3>最近一场观看人数</h3>
+            ${renderSparkline(result.data.map(sample => sample.viewers))}
+            <div class="meta">
+                <span>峰值 ${formatNumber(session.peak_viewers || 0)}</span>
+                <span>平均 ${formatNumber(session.avg_viewers || 0)}</span>
+            </div>
+        `;
+    } catch (error) {
+        console.error('Error fetching viewers:', error);
+    }
+}
+
+function renderSparkline(values) {
+    const width = 300;
+    const height = 48;
+    const max = Math.max(...values);
+    const min = Math.min(...values);
+    const range = max - min || 1;
+    const points = values.map((v, i) => {
+        const x = i / (values.length - 1) * width;
+        const y = height - 2 - (v - min) / range * (height - 4);
+        return `${x.toFixed(1)},${y.toFixed(1)}`;
+    }).join(' ');
+    return `<svg class="sparkline" viewBox="0 0 ${width} ${height}" preserveAspectRatio="none"><polyline points="${points}" /></svg>`;
+}
+
+// Web Push: the browser subscription is shared, the server stores which
+// streamers it should be notified about
+async function getPushSubscription() {
+    const registration = await navigator.serviceWorker.register(basePath + '/sw.js', { scope: basePath + '/' });
+    await navigator.serviceWorker.ready;
+
+    let subscription = await registration.pushManager.getSubscription();
+    if (!subscription) {
+        const response = await fetch(basePath + '/api/push/key');
+        const result = await response.json();
+        if (result.code !== 0) {
+            throw new Error(result.message);
+        }
+        subscription = await registration.pushManager.subscribe({
+            userVisibleOnly: true,
+            applicationServerKey: urlBase64ToUint8Array(result.data.public_key)
+        });
+    }
+    return subscription;
+}
+
+async function togglePush(id) {
+    const next = new Set(pushStreamers);
+    if (next.has(id)) {
+        next.delete(id);
+    } else {
+        next.add(id);
+        if (Notification.permission !== 'granted' && await Notification.requestPermission() !== 'granted') {
+            alert('请允许浏览器通知权限');
+            return;
+        }
+    }
+
+    try {
+        const subscription = await getPushSubscription();
+        // Drop streamers that are no longer tracked
+        const selected = [...next].filter(sid => streamers.some(s => s.id === sid));
+        const response = await fetch(basePath + '/api/push/subscribe', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ subscription: subscription.toJSON(), streamers: selected })
+        });
+        const result = await response.json();
+        if (result.code !== 0) {
+            throw new Error(result.message);
+        }
+        pushStreamers = new Set(selected);
+        localStorage.setItem('pushStreamers', JSON.stringify(selected));
+        renderStreamers();
+    } catch (error) {
+        console.error('Error updating push subscription:', error);
+        alert('开播提醒设置失败');
+    }
+}
+
+function urlBase64ToUint8Array(base64String) {
+    const padding = '='.repeat((4 - base64String.length % 4) % 4);
+    const base64 = (base64String + padding).replace(/-/g, '+').replace(/_/g, '/');
+    const raw = atob(base64);
+    return Uint8Array.from(raw, c => c.charCodeAt(0));
+}
+
 function closeModal() {
     document.getElementById('statsModal').classList.remove('show');
 }
@@ -224,8 +362,66 @@ document.addEventListener('keydown', (e) => {
     }
 });
 
+// Live updates: status changes arrive over Server-Sent Events; polling is
+// the fallback when SSE is unavailable or disconnected
+const POLL_INTERVAL = 30000;
+// Query times are not pushed, so refresh them occasionally while on SSE
+const SSE_REFRESH_INTERVAL = 300000;
+let pollTimer = null;
+let pollInterval = 0;
+
+function startPolling(interval) {
+    if (pollInterval === interval) return;
+    clearInterval(pollTimer);
+    pollInterval = interval;
+    pollTimer = setInterval(fetchStreamers, interval);
+}
+
+function sortStreamers() {
+    // Same order as the API: live streamers first, then by name
+    streamers.sort((a, b) => {
+        if (a.is_live !== b.is_live) return a.is_live ? -1 : 1;
+        return a.name < b.name ? -1 : a.name > b.name ? 1 : 0;
+    });
+}
+
+function applyEvent(event) {
+    const idx = streamers.findIndex(s => s.id === event.streamer.id);
+    if (idx === -1) {
+        fetchStreamers();
+        return;
+    }
+    streamers[idx] = event.streamer;
+    sortStreamers();
+    renderStreamers();
+    updateStats();
+}
+
+function removeStreamer(id) {
+    streamers = streamers.filter(s => s.id !== id);
+    renderStreamers();
+    updateStats();
+}
+
+function connectEvents() {
+    if (!window.EventSource) {
+        startPolling(POLL_INTERVAL);
+        return;
+    }
+
+    const source = new EventSource(basePath + '/api/events');
+    source.onopen = () => startPolling(SSE_REFRESH_INTERVAL);
+    ['live_start', 'live_end', 'title_change', 'scan_failed'].forEach(type => {
+        source.addEventListener(type, e => applyEvent(JSON.parse(e.data)));
+    });
+    source.addEventListener('streamer_added', e => applyEvent(JSON.parse(e.data)));
+    source.addEventListener('streamer_removed', e => removeStreamer(JSON.parse(e.data).streamer.id));
+    // Sent when missed events are no longer buffered on the server
+    source.addEventListener('reset', fetchStreamers);
+    // The browser keeps reconnecting on its own; poll until it succeeds
+    source.onerror = () => startPolling(POLL_INTERVAL);
+}
+
 // Initial fetch
 fetchStreamers();
-
-// Auto refresh every 30 seconds
-setInterval(fetchStreamers, 30000);
+connectEvents();
@@ -0,0 +1,23 @@
+// Service worker for live start push notifications
+
+self.addEventListener('push', (event) => {
+    const data = event.data ? event.data.json() : {};
+    event.waitUntil(
+        self.registration.showNotification(data.title || '抽象赛道⏰', {
+            body: data.body || '',
+            // Local avatar paths are relative to the site, which may live
+            // under a sub path
+            icon: data.icon && data.icon.startsWith('/')
+                ? new URL('.' + data.icon, self.registration.scope).href
+                : data.icon,
+            tag: data.tag,
+            data: { url: data.url }
+        })
+    );
+});
+
+self.addEventListener('notificationclick', (event) => {
+    event.notification.close();
+    const url = (event.notification.data && event.notification.data.url) || self.registration.scope;
+    event.waitUntil(clients.openWindow(url));
+});
@@ -0,0 +1,109 @@
+// Package web holds the site's page and static files, embedded into the
+// binary so a deployment only needs the executable and its config.
+package web
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+//go:embed *.html *.js *.css
+var embedded embed.FS
+
+// immutable lets browsers keep a file for a year without revalidating. Only
+// used for URLs carrying the file's content hash.
+const immutable = "public, max-age=31536000, immutable"
+
+// Assets serves the web files, either the embedded copy or a directory on
+// disk for development. Files from a directory are re-read on every
+// request, so edits show up on reload.
+type Assets struct {
+	fsys fs.FS
+	dev  bool
+
+	mu     sync.Mutex
+	hashes map[string]string
+	tmpl   *template.Template
+}
+
+// New returns the embedded assets, or those in dir if it is not empty.
+func New(dir string) (*Assets, error) {
+	a := &Assets{fsys: embedded, hashes: make(map[string]string)}
+	if dir != "" {
+		if _, err := os.Stat(dir); err != nil {
+			return nil, err
+		}
+		a.fsys = os.DirFS(dir)
+		a.dev = true
+	}
+	// Fail on startup rather than on the first request
+	if _, err := a.Template(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Hash returns a short content hash of the named file for cache busting
+// URLs, or "" if it does not exist.
+func (a *Assets) Hash(name string) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if hash, ok := a.hashes[name]; ok && !a.dev {
+		return hash
+	}
+	data, err := fs.ReadFile(a.fsys, name)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:6])
+	a.hashes[name] = hash
+	return hash
+}
+
+// Template returns the parsed page templates.
+func (a *Assets) Template() (*template.Template, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.tmpl != nil && !a.dev {
+		return a.tmpl, nil
+	}
+	tmpl, err := template.ParseFS(a.fsys, "*.html")
+	if err != nil {
+		return nil, err
+	}
+	a.tmpl = tmpl
+	return tmpl, nil
+}
+
+// ServeFile writes the named file. A request whose v parameter matches the
+// file's current hash may be cached forever, anything else is revalidated.
+// Templates are not served.
+func (a *Assets) ServeFile(w http.ResponseWriter, r *http.Request, name string) {
+	name = strings.TrimPrefix(name, "/")
+	if path.Ext(name) == ".html" {
+		http.NotFound(w, r)
+		return
+	}
+	if info, err := fs.Stat(a.fsys, name); err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	if v := r.URL.Query().Get("v"); v != "" && v == a.Hash(name) && !a.dev {
+		w.Header().Set("Cache-Control", immutable)
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	http.ServeFileFS(w, r, a.fsys, name)
+}